@@ -0,0 +1,190 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyAcmePropagationTimeout = "propagation-timeout"
+	keyAcmePollInterval       = "poll-interval"
+
+	acmeChallengeLabel = "_acme-challenge"
+	envCertbotDomain   = "CERTBOT_DOMAIN"
+	envCertbotToken    = "CERTBOT_VALIDATION"
+)
+
+var (
+	requiredAcmeFlags = []string{keyCommonUsername}
+
+	acmeCmd = &cobra.Command{
+		Use:   "acme",
+		Short: "ACME DNS-01 challenge hooks for certbot and lego",
+		Long: `ACME DNS-01 challenge hooks for certbot and lego
+
+The challenge is read either from the arguments, as lego's 'exec' provider passes them: <fqdn> <value>
+or from the CERTBOT_DOMAIN and CERTBOT_VALIDATION env vars set by certbot's --manual-auth-hook and --manual-cleanup-hook.
+Only the TXT record with the challenge value is added or removed, sibling TXT records are kept.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	acmePresentCmd = &cobra.Command{
+		Use:   "present [fqdn value]",
+		Short: "Add the challenge TXT record",
+		Args:  cobra.RangeArgs(0, 2),
+		Run:   RunAcmePresent,
+	}
+
+	acmeCleanupCmd = &cobra.Command{
+		Use:   "cleanup [fqdn value]",
+		Short: "Remove the challenge TXT record",
+		Args:  cobra.RangeArgs(0, 2),
+		Run:   RunAcmeCleanup,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(acmeCmd)
+	acmeCmd.AddCommand(acmePresentCmd, acmeCleanupCmd)
+
+	for _, c := range []*cobra.Command{acmePresentCmd, acmeCleanupCmd} {
+		addCommonFlags(c)
+		c.Flags().StringP(keyCommonTld, "t", "", "Namecheap top-level domain, e.g.: 'com'. Derived from the challenge domain if omitted")
+		c.Flags().StringP(keyCommonSld, "s", "", "Namecheap second-level domain, e.g.: 'example'. Derived from the challenge domain if omitted")
+		c.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+		addBackupFlags(c)
+		addNameserversFlags(c)
+	}
+	acmePresentCmd.Flags().String(setOneKeyTTL, "60", "Time to live in seconds of the challenge record")
+	acmePresentCmd.Flags().Duration(keyAcmePropagationTimeout, 0, "Wait up to this long for the record to be visible in public DNS. If 0, does not wait")
+	acmePresentCmd.Flags().Duration(keyAcmePollInterval, 10*time.Second, "How often to check public DNS while waiting for propagation")
+
+	config.ViperBindPFlagSet(acmePresentCmd, nil)
+	config.ViperBindPFlagSet(acmeCleanupCmd, nil)
+}
+
+// RunAcmePresent adds the challenge TXT record next to any existing ones
+func RunAcmePresent(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredAcmeFlags)
+
+	fqdn, challenge := acmeChallenge(cmd, args)
+	timeout := config.ViperGetDuration(cmd, keyGetTimeout)
+
+	apiresponse := download(cmd, timeout)
+	apiresponse.CommandResponse.DomainDNSGetHostsResult.Host = appendHost(
+		apiresponse.CommandResponse.DomainDNSGetHostsResult.Host,
+		challenge,
+	)
+	upload(cmd, apiresponse, timeout)
+
+	if wait := config.ViperGetDuration(cmd, keyAcmePropagationTimeout); wait > 0 {
+		waitForTXT(fqdn, challenge.Address, wait, config.ViperGetDuration(cmd, keyAcmePollInterval))
+	}
+}
+
+// RunAcmeCleanup removes the challenge TXT record, keeping any sibling TXT records
+func RunAcmeCleanup(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredAcmeFlags)
+
+	_, challenge := acmeChallenge(cmd, args)
+	timeout := config.ViperGetDuration(cmd, keyGetTimeout)
+
+	apiresponse := download(cmd, timeout)
+	hosts, removed := removeHost(apiresponse.CommandResponse.DomainDNSGetHostsResult.Host, challenge)
+	if removed == 0 {
+		log.Infof("Challenge record '%s' was already removed", challenge.Name)
+		return
+	}
+	apiresponse.CommandResponse.DomainDNSGetHostsResult.Host = hosts
+	upload(cmd, apiresponse, timeout)
+}
+
+// acmeChallenge reads the challenge from lego style arguments or certbot env vars,
+// sets sld and tld when missing and returns the record fqdn and the TXT host to add or remove
+func acmeChallenge(cmd *cobra.Command, args []string) (string, namecheap.Host) {
+	var fqdn, value string
+	switch len(args) {
+	case 2:
+		fqdn, value = args[0], args[1]
+	case 0:
+		domain, token := os.Getenv(envCertbotDomain), os.Getenv(envCertbotToken)
+		if len(domain) == 0 || len(token) == 0 {
+			log.Fatalf("Expected either <fqdn> <value> arguments or %s and %s env vars", envCertbotDomain, envCertbotToken)
+		}
+		fqdn, value = fmt.Sprintf("%s.%s", acmeChallengeLabel, strings.TrimPrefix(domain, "*.")), token
+	default:
+		log.Fatalf("Expected either <fqdn> <value> arguments or %s and %s env vars", envCertbotDomain, envCertbotToken)
+	}
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+
+	sld := config.ViperGetString(cmd, keyCommonSld)
+	tld := config.ViperGetString(cmd, keyCommonTld)
+	if len(sld) == 0 || len(tld) == 0 {
+		labels := strings.Split(fqdn, ".")
+		if len(labels) < 3 {
+			log.Fatalf("Cannot derive the domain from '%s', please specify --%s and --%s", fqdn, keyCommonSld, keyCommonTld)
+		}
+		sld, tld = labels[len(labels)-2], labels[len(labels)-1]
+		config.ViperSet(cmd, keyCommonSld, sld)
+		config.ViperSet(cmd, keyCommonTld, tld)
+	}
+
+	domain := fmt.Sprintf("%s.%s", sld, tld)
+	if !strings.HasSuffix(fqdn, "."+domain) {
+		log.Fatalf("'%s' is not a subdomain of '%s'", fqdn, domain)
+	}
+
+	ttl := namecheap.NewInt(60)
+	if cmd.Flags().Lookup(setOneKeyTTL) != nil {
+		ttl = intFlag(cmd, setOneKeyTTL)
+	}
+	return fqdn, namecheap.Host{
+		Name:     strings.TrimSuffix(fqdn, "."+domain),
+		Type:     namecheap.RecordTypeTXT,
+		Address:  value,
+		TTL:      ttl,
+		IsActive: namecheap.NewBool(true),
+	}
+}
+
+// waitForTXT polls public DNS until fqdn has a TXT record with value, or gives up after timeout
+func waitForTXT(fqdn, value string, timeout, interval time.Duration) {
+	log.Infof("Waiting up to %s for '%s' to propagate", timeout, fqdn)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		records, err := net.DefaultResolver.LookupTXT(ctx, fqdn)
+		if err != nil {
+			log.Debugf("TXT lookup of '%s' failed: %v", fqdn, err)
+		}
+		for _, r := range records {
+			if r == value {
+				log.Info("Challenge record is visible in public DNS")
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Warnf("Challenge record is not visible in public DNS after %s, continuing anyway", timeout)
+			return
+		case <-time.After(interval):
+		}
+	}
+}
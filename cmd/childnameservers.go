@@ -0,0 +1,156 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyNsIP    = "ip"
+	keyNsOldIP = "old-ip"
+)
+
+var (
+	requiredNsFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld}
+
+	nsCmd = &cobra.Command{
+		Use:   "ns",
+		Short: "Manage child nameservers (glue records) of a domain, e.g.: ns1.example.com",
+		Long: `Manage child nameservers (glue records) of a domain, e.g.: ns1.example.com
+
+Registering them is needed to delegate a domain to nameservers under itself with 'nameservers set-custom'.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	nsCreateCmd = &cobra.Command{
+		Use:   "create <nameserver>",
+		Short: "Register a child nameserver with its IP address",
+		Long:  `Register a child nameserver with its IP address, e.g.: namecheap-cli ns create -s example -t com ns1.example.com --ip 192.0.2.1`,
+		Args:  cobra.ExactArgs(1),
+		Run:   RunNsCreate,
+	}
+
+	nsDeleteCmd = &cobra.Command{
+		Use:   "delete <nameserver>",
+		Short: "Remove a child nameserver",
+		Args:  cobra.ExactArgs(1),
+		Run:   RunNsDelete,
+	}
+
+	nsInfoCmd = &cobra.Command{
+		Use:   "info <nameserver>",
+		Short: "Show the IP address and statuses of a child nameserver",
+		Args:  cobra.ExactArgs(1),
+		Run:   RunNsInfo,
+	}
+
+	nsUpdateCmd = &cobra.Command{
+		Use:   "update <nameserver>",
+		Short: "Change the IP address of a child nameserver",
+		Long: `Change the IP address of a child nameserver, e.g.: namecheap-cli ns update -s example -t com ns1.example.com --ip 192.0.2.2
+
+The current address is looked up when --old-ip is omitted.`,
+		Args: cobra.ExactArgs(1),
+		Run:  RunNsUpdate,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(nsCmd)
+	nsCmd.AddCommand(nsCreateCmd, nsDeleteCmd, nsInfoCmd, nsUpdateCmd)
+
+	for _, c := range []*cobra.Command{nsCreateCmd, nsDeleteCmd, nsInfoCmd, nsUpdateCmd} {
+		addCommonFlags(c)
+		c.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
+		c.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
+		c.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+		c.Flags().String(keyGetOutputFormat, responseFormats[0], fmt.Sprintf("Output format. Supported: %v", responseFormats))
+		c.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+		c.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+	}
+	nsCreateCmd.Flags().String(keyNsIP, "", "[Required] IPv4 address of the nameserver")
+	nsUpdateCmd.Flags().String(keyNsIP, "", "[Required] New IPv4 address of the nameserver")
+	nsUpdateCmd.Flags().String(keyNsOldIP, "", "Current IPv4 address of the nameserver. If omitted, it is looked up")
+
+	for _, c := range []*cobra.Command{nsCreateCmd, nsDeleteCmd, nsInfoCmd, nsUpdateCmd} {
+		config.ViperBindPFlagSet(c, nil)
+	}
+}
+
+// RunNsCreate registers the child nameserver given as argument
+func RunNsCreate(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, append(requiredNsFlags, keyNsIP))
+
+	runNs(cmd, "create", func(ctx context.Context, client *namecheap.Client, params *requestParameters) (*namecheap.ChildNameserverResponse, error) {
+		return client.CreateNameserver(ctx, params.sld, params.tld, args[0], config.ViperGetString(cmd, keyNsIP))
+	})
+}
+
+// RunNsDelete removes the child nameserver given as argument
+func RunNsDelete(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredNsFlags)
+
+	runNs(cmd, "delete", func(ctx context.Context, client *namecheap.Client, params *requestParameters) (*namecheap.ChildNameserverResponse, error) {
+		return client.DeleteNameserver(ctx, params.sld, params.tld, args[0])
+	})
+}
+
+// RunNsInfo shows the child nameserver given as argument
+func RunNsInfo(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredNsFlags)
+
+	runNs(cmd, "get the info of", func(ctx context.Context, client *namecheap.Client, params *requestParameters) (*namecheap.ChildNameserverResponse, error) {
+		return client.GetNameserverInfo(ctx, params.sld, params.tld, args[0])
+	})
+}
+
+// RunNsUpdate changes the address of the child nameserver given as argument
+func RunNsUpdate(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, append(requiredNsFlags, keyNsIP))
+
+	runNs(cmd, "update", func(ctx context.Context, client *namecheap.Client, params *requestParameters) (*namecheap.ChildNameserverResponse, error) {
+		oldIP := config.ViperGetString(cmd, keyNsOldIP)
+		if len(oldIP) == 0 {
+			info, err := client.GetNameserverInfo(ctx, params.sld, params.tld, args[0])
+			if err != nil {
+				return nil, err
+			}
+			oldIP = info.CommandResponse.Result.IP
+			log.Infof("Current address of '%s' is %s", args[0], oldIP)
+		}
+		return client.UpdateNameserver(ctx, params.sld, params.tld, args[0], oldIP, config.ViperGetString(cmd, keyNsIP))
+	})
+}
+
+// runNs performs one of the child nameserver commands and outputs the response in the specified format
+func runNs(cmd *cobra.Command, action string, fn func(context.Context, *namecheap.Client, *requestParameters) (*namecheap.ChildNameserverResponse, error)) {
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(responseFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, responseFormats)
+	}
+
+	params := setCommonParameters(cmd)
+	response, err := fn(context.Background(), newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout)), params)
+	if err != nil {
+		log.Fatalf("Failed to %s the child nameserver: %v", action, err)
+	}
+
+	output, err := encode(format, response)
+	if err != nil {
+		log.Fatalf("Failed to marshal format '%s': %s", format, err)
+	}
+	writeOutput(cmd, &output)
+}
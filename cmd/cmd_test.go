@@ -0,0 +1,168 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thedataflows/namecheap-cli/pkg/mockserver"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// startMock serves s and returns the flags pointing commands at its example.com domain
+func startMock(t *testing.T, s *mockserver.Server) []string {
+	t.Helper()
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return []string{
+		"--api-url", server.URL,
+		"-u", "user",
+		"-k", "key",
+		"-s", "example",
+		"-t", "com",
+	}
+}
+
+// uploadArgs keep commands uploading hosts from writing outside of the test
+var uploadArgs = []string{"--backup-dir="}
+
+// execute runs a subcommand as the command line would. Flags keep their values between runs, so they are reset first
+func execute(t *testing.T, args ...string) {
+	t.Helper()
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetFlags(cmd)
+	rootCmd.SetArgs(args)
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+}
+
+func host(name string, recordType namecheap.RecordType, address string) namecheap.Host {
+	return namecheap.Host{Name: name, Type: recordType, Address: address, TTL: namecheap.NewInt(1799)}
+}
+
+// checkHosts fails when the hosts of the mock domain differ from want, ignoring ids
+func checkHosts(t *testing.T, s *mockserver.Server, want ...namecheap.Host) {
+	t.Helper()
+	if diff := namecheap.DiffHosts(s.Hosts("example.com"), want); !diff.IsEmpty() {
+		t.Errorf("hosts differ from the expected ones:\n%s", diff)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := mockserver.New(mockserver.WithCredentials("user", "key"), mockserver.WithDomain("example.com", "MX",
+		host("@", "A", "192.0.2.1"),
+		host("@", "TXT", "v=spf1 a, ~all"),
+	))
+	output := filepath.Join(t.TempDir(), "example.com.zone")
+
+	execute(t, append([]string{"get", "--output-format", "bind", "-o", output}, startMock(t, s)...)...)
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "$ORIGIN example.com.\n$TTL 1799\n@\t1799\tIN\tA\t192.0.2.1\n@\t1799\tIN\tTXT\t\"v=spf1 a, ~all\"\n"
+	if string(data) != want {
+		t.Errorf("get wrote\n%q\nwant\n%q", data, want)
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := mockserver.New(mockserver.WithCredentials("user", "key"), mockserver.WithDomain("example.com", "MX",
+		host("@", "A", "192.0.2.1"),
+		host("old", "A", "192.0.2.9"),
+	))
+	input := filepath.Join(t.TempDir(), "example.com.zone")
+	if err := os.WriteFile(input, []byte("$ORIGIN example.com.\n@ 1799 A 192.0.2.1\nwww 300 CNAME @\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args := append(append([]string{"set", "--input-format", "bind", "-i", input}, startMock(t, s)...), uploadArgs...)
+
+	// a dry run uploads nothing
+	execute(t, append(args, "--dry-run")...)
+	checkHosts(t, s, host("@", "A", "192.0.2.1"), host("old", "A", "192.0.2.9"))
+
+	execute(t, append(args, "--auto-approve")...)
+	www := host("www", "CNAME", "example.com.")
+	www.TTL = namecheap.NewInt(300)
+	checkHosts(t, s, host("@", "A", "192.0.2.1"), www)
+}
+
+func TestSetOne(t *testing.T) {
+	s := mockserver.New(mockserver.WithCredentials("user", "key"), mockserver.WithDomain("example.com", "MX",
+		host("@", "A", "192.0.2.1"),
+	))
+	common := append(startMock(t, s), uploadArgs...)
+
+	steps := []struct {
+		name string
+		args []string
+		want []namecheap.Host
+	}{
+		{
+			name: "append a new name",
+			args: []string{"--name", "www", "--type", "cname", "--address", "example.com."},
+			want: []namecheap.Host{host("@", "A", "192.0.2.1"), host("www", "CNAME", "example.com.")},
+		},
+		{
+			name: "update the first match",
+			args: []string{"--name", "@", "--type", "A", "--address", "192.0.2.2"},
+			want: []namecheap.Host{host("@", "A", "192.0.2.2"), host("www", "CNAME", "example.com.")},
+		},
+		{
+			name: "add a sibling",
+			args: []string{"--name", "@", "--type", "A", "--address", "192.0.2.3", "--add"},
+			want: []namecheap.Host{host("@", "A", "192.0.2.2"), host("@", "A", "192.0.2.3"), host("www", "CNAME", "example.com.")},
+		},
+		{
+			name: "delete one sibling",
+			args: []string{"--name", "@", "--type", "A", "--address", "-", "--match-address", "192.0.2.2", "--delete"},
+			want: []namecheap.Host{host("@", "A", "192.0.2.3"), host("www", "CNAME", "example.com.")},
+		},
+		{
+			name: "replace all",
+			args: []string{"--name", "www", "--type", "CNAME", "--address", "example.net.", "--replace-all", "--ttl", "600"},
+			want: []namecheap.Host{host("@", "A", "192.0.2.3"), {Name: "www", Type: namecheap.RecordTypeCNAME, Address: "example.net.", TTL: namecheap.NewInt(600)}},
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			execute(t, append(append([]string{"setone"}, step.args...), common...)...)
+			checkHosts(t, s, step.want...)
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	s := mockserver.New(mockserver.WithCredentials("user", "key"), mockserver.WithDomain("example.com", "MX",
+		host("@", "A", "192.0.2.1"),
+	))
+	common := append(startMock(t, s), "--backup-dir", t.TempDir())
+
+	execute(t, append([]string{"setone", "--name", "@", "--type", "A", "--address", "192.0.2.2"}, common...)...)
+	execute(t, append([]string{"setone", "--name", "www", "--type", "CNAME", "--address", "example.com."}, common...)...)
+	checkHosts(t, s, host("@", "A", "192.0.2.2"), host("www", "CNAME", "example.com."))
+
+	// snapshot 2 is the configuration before the first change
+	execute(t, append([]string{"rollback", "--snapshot", "2", "--auto-approve"}, common...)...)
+	checkHosts(t, s, host("@", "A", "192.0.2.1"))
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/thedataflows/go-commons/pkg/config"
 	"github.com/thedataflows/go-commons/pkg/file"
 	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/compact"
 	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"github.com/thedataflows/namecheap-cli/pkg/zonefile"
 	"gopkg.in/yaml.v3"
 	"k8s.io/utils/strings/slices"
 
@@ -117,11 +119,21 @@ func unmarshal(inputFormat string, input *[]byte) *namecheap.ApiResponse {
 	)
 	switch inputFormat {
 	case supportedFormats[0]:
-		err = xml.Unmarshal(*input, inputMarshalled)
-	case supportedFormats[1]:
-		err = yaml.Unmarshal(*input, inputMarshalled)
-	case supportedFormats[2]:
-		err = json.Unmarshal(*input, inputMarshalled)
+		err = decode(inputFormat, *input, inputMarshalled)
+	case supportedFormats[1], supportedFormats[2], supportedFormats[4]:
+		// YAML and JSON files can hold either the API envelope or a compact zone
+		switch {
+		case compact.Detect(*input):
+			err = compact.Unmarshal(*input, inputMarshalled)
+		case inputFormat == supportedFormats[4]:
+			err = yaml.Unmarshal(*input, inputMarshalled)
+		default:
+			err = decode(inputFormat, *input, inputMarshalled)
+		}
+	case supportedFormats[3]:
+		var warnings []string
+		warnings, err = zonefile.Unmarshal(*input, inputMarshalled)
+		logWarnings(warnings)
 	}
 	if err != nil {
 		log.Fatalf("Failed to unmarshal: %s", err)
@@ -136,12 +148,14 @@ func marshal(format string, apiresponse *namecheap.ApiResponse) *[]byte {
 		err    error
 	)
 	switch format {
-	case supportedFormats[0]:
-		output, err = xml.MarshalIndent(apiresponse, "", "  ")
-	case supportedFormats[1]:
-		output, err = yaml.Marshal(apiresponse)
-	case supportedFormats[2]:
-		output, err = json.MarshalIndent(apiresponse, "", "  ")
+	case supportedFormats[0], supportedFormats[1], supportedFormats[2]:
+		output, err = encode(format, apiresponse)
+	case supportedFormats[3]:
+		var warnings []string
+		output, warnings, err = zonefile.Marshal(apiresponse)
+		logWarnings(warnings)
+	case supportedFormats[4]:
+		output, err = compact.Marshal(apiresponse)
 	}
 	if err != nil {
 		log.Fatalf("Failed to marshal format '%s': %s", format, err)
@@ -149,6 +163,39 @@ func marshal(format string, apiresponse *namecheap.ApiResponse) *[]byte {
 	return &output
 }
 
+// encode marshals v to XML, YAML or JSON
+func encode(format string, v interface{}) ([]byte, error) {
+	switch format {
+	case supportedFormats[0]:
+		return xml.MarshalIndent(v, "", "  ")
+	case supportedFormats[1]:
+		return yaml.Marshal(v)
+	case supportedFormats[2]:
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return nil, fmt.Errorf("format '%s' is not one of %v", format, responseFormats)
+}
+
+// decode unmarshals XML, YAML or JSON data into v
+func decode(format string, data []byte, v interface{}) error {
+	switch format {
+	case supportedFormats[0]:
+		return xml.Unmarshal(data, v)
+	case supportedFormats[1]:
+		return yaml.Unmarshal(data, v)
+	case supportedFormats[2]:
+		return json.Unmarshal(data, v)
+	}
+	return fmt.Errorf("format '%s' is not one of %v", format, responseFormats)
+}
+
+// logWarnings logs conversion warnings
+func logWarnings(warnings []string) {
+	for _, w := range warnings {
+		log.Warn(w)
+	}
+}
+
 // writeOutput writes to a specified file or stdout
 func writeOutput(cmd *cobra.Command, output *[]byte) {
 	outputFileName := config.ViperGetString(cmd, keyGetOutputFile)
@@ -0,0 +1,231 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/ddns"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyDdnsHosts     = "hosts"
+	keyDdnsIPv4      = "ipv4"
+	keyDdnsIPv6      = "ipv6"
+	keyDdnsIPv4URLs  = "ipv4-urls"
+	keyDdnsIPv6URLs  = "ipv6-urls"
+	keyDdnsInterface = "interface"
+	keyDdnsInterval  = "interval"
+	keyDdnsJitter    = "jitter"
+	keyDdnsStateFile = "state-file"
+)
+
+var (
+	requiredDdnsFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld, keyDdnsHosts}
+
+	ddnsCmd = &cobra.Command{
+		Use:     "ddns",
+		Short:   "Update A/AAAA records with the current public IP address",
+		Long:    ``,
+		Aliases: []string{"d"},
+		Run:     RunDdns,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(ddnsCmd)
+
+	addCommonFlags(ddnsCmd)
+	ddnsCmd.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
+	ddnsCmd.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
+
+	ddnsCmd.Flags().String(keyDdnsHosts, "", "[Required] Comma separated record names to update, e.g.: '@,www'")
+	ddnsCmd.Flags().Bool(keyDdnsIPv4, true, "Update A records with the public IPv4 address")
+	ddnsCmd.Flags().Bool(keyDdnsIPv6, false, "Update AAAA records with the public IPv6 address")
+	ddnsCmd.Flags().String(keyDdnsIPv4URLs, strings.Join(ddns.DefaultIPv4URLs, ","), "Comma separated HTTP endpoints echoing the public IPv4 address, tried in order")
+	ddnsCmd.Flags().String(keyDdnsIPv6URLs, strings.Join(ddns.DefaultIPv6URLs, ","), "Comma separated HTTP endpoints echoing the public IPv6 address, tried in order")
+	ddnsCmd.Flags().String(keyDdnsInterface, "", "Read the addresses from this local network interface instead of the HTTP endpoints")
+	ddnsCmd.Flags().Duration(keyDdnsInterval, 0, "Check again after this interval, e.g.: '5m'. If 0, runs once")
+	ddnsCmd.Flags().Duration(keyDdnsJitter, 30*time.Second, "Random delay of up to this duration added to each interval")
+	ddnsCmd.Flags().String(keyDdnsStateFile, "", "File remembering the last published addresses, so restarts do not trigger needless API calls")
+	ddnsCmd.Flags().String(setOneKeyTTL, "1799", "Time to live in seconds for created records. 1799 is Namecheap's equivalent to 'Automatic'")
+	ddnsCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+	addBackupFlags(ddnsCmd)
+	addNameserversFlags(ddnsCmd)
+
+	config.ViperBindPFlagSet(ddnsCmd, nil)
+}
+
+// RunDdns publishes the current public addresses once or, with an interval, until the process is stopped
+func RunDdns(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredDdnsFlags)
+
+	hosts := splitList(config.ViperGetString(cmd, keyDdnsHosts))
+	if len(hosts) == 0 {
+		log.Fatalf("--%s must contain at least one record name", keyDdnsHosts)
+	}
+	var recordTypes []string
+	if config.ViperGetBool(cmd, keyDdnsIPv4) {
+		recordTypes = append(recordTypes, ddns.TypeA)
+	}
+	if config.ViperGetBool(cmd, keyDdnsIPv6) {
+		recordTypes = append(recordTypes, ddns.TypeAAAA)
+	}
+	if len(recordTypes) == 0 {
+		log.Fatalf("Both --%s and --%s are disabled, nothing to do", keyDdnsIPv4, keyDdnsIPv6)
+	}
+
+	interval := config.ViperGetDuration(cmd, keyDdnsInterval)
+	jitter := config.ViperGetDuration(cmd, keyDdnsJitter)
+	for {
+		if err := updateDdns(cmd, hosts, recordTypes); err != nil {
+			if interval == 0 {
+				log.Fatal(err)
+			}
+			log.Errorf("Dynamic DNS update failed: %v", err)
+		}
+		if interval == 0 {
+			return
+		}
+
+		wait := interval
+		if jitter > 0 {
+			// #nosec G404 -- jitter does not need a secure random source
+			wait += time.Duration(rand.Int63n(int64(jitter)))
+		}
+		log.Debugf("Next check in %s", wait)
+		time.Sleep(wait)
+	}
+}
+
+// updateDdns detects the current addresses and uploads them when they differ from the records
+func updateDdns(cmd *cobra.Command, hosts []string, recordTypes []string) error {
+	params := setCommonParameters(cmd)
+	timeout := config.ViperGetDuration(cmd, keyGetTimeout)
+	domain := fmt.Sprintf("%s.%s", params.sld, params.tld)
+	stateFile := config.ViperGetString(cmd, keyDdnsStateFile)
+	ctx := context.Background()
+
+	state := ddns.State{}
+	if len(stateFile) > 0 {
+		var err error
+		state, err = ddns.LoadState(stateFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	addresses := map[string]string{}
+	stale := false
+	for _, recordType := range recordTypes {
+		ip, err := detectAddress(ctx, cmd, recordType, time.Second*timeout)
+		if err != nil {
+			return err
+		}
+		addresses[recordType] = ip.String()
+		log.Debugf("Detected %s address %s", recordType, ip)
+
+		for _, host := range hosts {
+			if state[ddns.StateKey(domain, host, recordType)] != ip.String() {
+				stale = true
+			}
+		}
+	}
+	if !stale {
+		log.Info("Addresses did not change since the last update")
+		return nil
+	}
+
+	client := newClient(params, time.Second*timeout)
+	apiresponse, err := client.GetHosts(ctx, params.sld, params.tld)
+	if err != nil {
+		return fmt.Errorf("failed to download DNS configuration: %w", err)
+	}
+	if !apiresponse.UsesOurDNS() && refuseCustomNameservers(cmd) {
+		return fmt.Errorf("'%s' uses custom nameservers, so updated records would have no effect. Use --%s to update them anyway", domain, keyAllowCustomNameservers)
+	}
+
+	records := append([]namecheap.Host(nil), apiresponse.CommandResponse.DomainDNSGetHostsResult.Host...)
+	changed := false
+	for _, recordType := range recordTypes {
+		for _, host := range hosts {
+			if hasAddress(records, host, recordType, addresses[recordType]) {
+				continue
+			}
+			log.Infof("Setting %s record '%s' to %s", recordType, host, addresses[recordType])
+			records = mergeHost(records, namecheap.Host{
+				Name:     host,
+				Type:     namecheap.RecordType(recordType),
+				Address:  addresses[recordType],
+				TTL:      intFlag(cmd, setOneKeyTTL),
+				IsActive: namecheap.NewBool(true),
+			}, false)
+			changed = true
+		}
+	}
+
+	if changed {
+		backupCurrent(ctx, cmd, client, params, apiresponse)
+		response, err := client.SetHosts(ctx, params.sld, params.tld, records, apiresponse.CommandResponse.DomainDNSGetHostsResult.EmailType)
+		if err != nil {
+			return fmt.Errorf("failed to upload DNS configuration: %w", err)
+		}
+		log.Infof("Success. Execution time: %s", response.ExecutionTime)
+	} else {
+		log.Info("Records already point to the current addresses")
+	}
+
+	if len(stateFile) == 0 {
+		return nil
+	}
+	for _, recordType := range recordTypes {
+		for _, host := range hosts {
+			state[ddns.StateKey(domain, host, recordType)] = addresses[recordType]
+		}
+	}
+	return state.Save(stateFile)
+}
+
+// detectAddress returns the current address of recordType from the configured interface or HTTP endpoints
+func detectAddress(ctx context.Context, cmd *cobra.Command, recordType string, timeout time.Duration) (net.IP, error) {
+	if iface := config.ViperGetString(cmd, keyDdnsInterface); len(iface) > 0 {
+		return ddns.DetectFromInterface(iface, recordType)
+	}
+	urlsKey := keyDdnsIPv4URLs
+	if recordType == ddns.TypeAAAA {
+		urlsKey = keyDdnsIPv6URLs
+	}
+	return ddns.DetectFromURLs(ctx, recordType, splitList(config.ViperGetString(cmd, urlsKey)), timeout)
+}
+
+// hasAddress reports whether the first record matching name and type, the one mergeHost would update, has address
+func hasAddress(hosts []namecheap.Host, name, recordType, address string) bool {
+	for _, host := range hosts {
+		if host.Name == name && string(host.Type) == recordType {
+			return host.Address == address
+		}
+	}
+	return false
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
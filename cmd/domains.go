@@ -0,0 +1,341 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"gopkg.in/yaml.v3"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyDomainsListType = "list-type"
+	keyDomainsSearch   = "search"
+	keyDomainsSortBy   = "sort-by"
+	keyDomainsPage     = "page"
+	keyDomainsPageSize = "page-size"
+	keyDomainsLocked   = "locked"
+	keyDomainsWithin   = "within"
+	keyDomainsSkipAuto = "skip-auto-renew"
+)
+
+var (
+	requiredDomainsFlags = []string{keyCommonUsername}
+
+	domainsFormats = []string{"table", "json", "yaml", "csv"}
+
+	domainsCmd = &cobra.Command{
+		Use:   "domains",
+		Short: "Inspect the domains of the Namecheap account",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	domainsListCmd = &cobra.Command{
+		Use:     "list",
+		Short:   "List the domains of the account",
+		Long:    `List the domains of the account, e.g.: namecheap-cli domains list --list-type expiring --output-format csv`,
+		Aliases: []string{"ls"},
+		Run:     RunDomainsList,
+	}
+
+	domainsInfoCmd = &cobra.Command{
+		Use:   "info <domain>...",
+		Short: "Show registration, lock, WhoisGuard and DNS details of domains",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   RunDomainsInfo,
+	}
+
+	domainsExpiringCmd = &cobra.Command{
+		Use:   "expiring",
+		Short: "Report domains expiring soon, exiting with an error when there is any",
+		Long: `Report domains expiring soon, exiting with an error when there is any
+
+Meant to run from cron, e.g.: namecheap-cli domains expiring --within 30d || notify-admins`,
+		Run: RunDomainsExpiring,
+	}
+)
+
+// domainSummary is the output of 'domains info'
+type domainSummary struct {
+	Name        string   `json:"name" yaml:"name"`
+	Created     string   `json:"created" yaml:"created"`
+	Expires     string   `json:"expires" yaml:"expires"`
+	DaysLeft    string   `json:"daysLeft" yaml:"daysLeft"`
+	Locked      bool     `json:"locked" yaml:"locked"`
+	WhoisGuard  string   `json:"whoisGuard" yaml:"whoisGuard"`
+	DNSProvider string   `json:"dnsProvider" yaml:"dnsProvider"`
+	UsingOurDNS bool     `json:"usingOurDNS" yaml:"usingOurDNS"`
+	EmailType   string   `json:"emailType" yaml:"emailType"`
+	Nameservers []string `json:"nameservers" yaml:"nameservers"`
+}
+
+func init() {
+	rootCmd.AddCommand(domainsCmd)
+	domainsCmd.AddCommand(domainsListCmd, domainsInfoCmd, domainsExpiringCmd)
+
+	for _, c := range []*cobra.Command{domainsListCmd, domainsInfoCmd, domainsExpiringCmd} {
+		addCommonFlags(c)
+	}
+	domainsListCmd.Flags().String(keyDomainsListType, strings.ToLower(namecheap.ListTypeAll), fmt.Sprintf("Which domains to list, one of: %v", lowerAll(namecheap.ListTypes)))
+	domainsListCmd.Flags().String(keyDomainsSearch, "", "Only list domains containing this term")
+	domainsListCmd.Flags().String(keyDomainsSortBy, "", fmt.Sprintf("Sort order, one of: %v", lowerAll(namecheap.SortOrders)))
+	domainsListCmd.Flags().String(keyDomainsLocked, "", "Only list locked (true) or unlocked (false) domains")
+	domainsListCmd.Flags().Int(keyDomainsPage, 0, "Only fetch this page. If 0, all pages are fetched")
+	domainsListCmd.Flags().Int(keyDomainsPageSize, namecheap.MaxPageSize, fmt.Sprintf("Domains per page, between 10 and %d", namecheap.MaxPageSize))
+	domainsListCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+	domainsListCmd.Flags().String(keyGetOutputFormat, domainsFormats[0], fmt.Sprintf("Output format. Supported: %v", domainsFormats))
+	domainsListCmd.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+	domainsListCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+
+	domainsInfoCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+	domainsInfoCmd.Flags().String(keyGetOutputFormat, domainsFormats[0], fmt.Sprintf("Output format. Supported: %v", domainsFormats))
+	domainsInfoCmd.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+	domainsInfoCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+
+	domainsExpiringCmd.Flags().String(keyDomainsWithin, "30d", "Report domains expiring within this period, e.g.: '30d', '2w', '72h'")
+	domainsExpiringCmd.Flags().Bool(keyDomainsSkipAuto, false, "Ignore domains with auto-renew enabled")
+	domainsExpiringCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+	domainsExpiringCmd.Flags().String(keyGetOutputFormat, domainsFormats[0], fmt.Sprintf("Output format. Supported: %v", domainsFormats))
+	domainsExpiringCmd.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+	domainsExpiringCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+
+	config.ViperBindPFlagSet(domainsListCmd, nil)
+	config.ViperBindPFlagSet(domainsInfoCmd, nil)
+	config.ViperBindPFlagSet(domainsExpiringCmd, nil)
+}
+
+// RunDomainsList lists the domains of the account in the specified format
+func RunDomainsList(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredDomainsFlags)
+
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(domainsFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, domainsFormats)
+	}
+	locked, err := namecheap.ParseBool(config.ViperGetString(cmd, keyDomainsLocked))
+	if err != nil {
+		log.Fatalf("Invalid --%s: %v", keyDomainsLocked, err)
+	}
+	page, err := strconv.Atoi(config.ViperGetString(cmd, keyDomainsPage))
+	if err != nil {
+		log.Fatalf("Invalid --%s: %v", keyDomainsPage, err)
+	}
+	pageSize, err := strconv.Atoi(config.ViperGetString(cmd, keyDomainsPageSize))
+	if err != nil {
+		log.Fatalf("Invalid --%s: %v", keyDomainsPageSize, err)
+	}
+
+	params := setCommonParameters(cmd)
+	client := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout))
+	domains, err := client.ListDomains(context.Background(), namecheap.DomainListOptions{
+		ListType:   config.ViperGetString(cmd, keyDomainsListType),
+		SearchTerm: config.ViperGetString(cmd, keyDomainsSearch),
+		SortBy:     config.ViperGetString(cmd, keyDomainsSortBy),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		log.Fatalf("Failed to list domains: %v", err)
+	}
+
+	if locked.Valid {
+		filtered := domains[:0]
+		for _, d := range domains {
+			if d.IsLocked.Value == locked.Value {
+				filtered = append(filtered, d)
+			}
+		}
+		domains = filtered
+	}
+	log.Infof("Found %d domains", len(domains))
+
+	writeOutput(cmd, marshalDomains(format, domains, time.Now()))
+}
+
+// RunDomainsInfo shows the details of each domain given as argument
+func RunDomainsInfo(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredDomainsFlags)
+
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(domainsFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, domainsFormats)
+	}
+
+	params := setCommonParameters(cmd)
+	client := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout))
+	ctx := context.Background()
+	now := time.Now()
+
+	summaries := make([]domainSummary, 0, len(args))
+	for _, name := range args {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		info, err := client.GetDomainInfo(ctx, name)
+		if err != nil {
+			log.Fatalf("Failed to get the details of '%s': %v", name, err)
+		}
+		locked, err := client.GetRegistrarLock(ctx, name)
+		if err != nil {
+			log.Fatalf("Failed to get the lock status of '%s': %v", name, err)
+		}
+		summaries = append(summaries, domainSummary{
+			Name:        info.DomainName,
+			Created:     info.DomainDetails.CreatedDate,
+			Expires:     info.DomainDetails.ExpiredDate,
+			DaysLeft:    daysLeft(info.DomainDetails.ExpiredDate, now),
+			Locked:      locked,
+			WhoisGuard:  info.Whoisguard.Enabled,
+			DNSProvider: info.DnsDetails.ProviderType,
+			UsingOurDNS: info.DnsDetails.IsUsingOurDNS.Value,
+			EmailType:   info.DnsDetails.EmailType,
+			Nameservers: info.DnsDetails.Nameserver,
+		})
+	}
+
+	columns := []string{"NAME", "CREATED", "EXPIRES", "DAYS LEFT", "LOCKED", "WHOISGUARD", "DNS", "OUR DNS", "EMAIL", "NAMESERVERS"}
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		rows = append(rows, []string{
+			s.Name, s.Created, s.Expires, s.DaysLeft, strconv.FormatBool(s.Locked), s.WhoisGuard,
+			s.DNSProvider, strconv.FormatBool(s.UsingOurDNS), s.EmailType, strings.Join(s.Nameservers, " "),
+		})
+	}
+	writeOutput(cmd, marshalTable(format, columns, rows, summaries))
+}
+
+// RunDomainsExpiring lists the domains expiring within the given period and fails when there is any
+func RunDomainsExpiring(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredDomainsFlags)
+
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(domainsFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, domainsFormats)
+	}
+	within, err := parsePeriod(config.ViperGetString(cmd, keyDomainsWithin))
+	if err != nil {
+		log.Fatalf("Invalid --%s: %v", keyDomainsWithin, err)
+	}
+	skipAutoRenew := config.ViperGetBool(cmd, keyDomainsSkipAuto)
+
+	params := setCommonParameters(cmd)
+	client := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout))
+	domains, err := client.ListDomains(context.Background(), namecheap.DomainListOptions{SortBy: "EXPIREDATE"})
+	if err != nil {
+		log.Fatalf("Failed to list domains: %v", err)
+	}
+
+	now := time.Now()
+	deadline := now.Add(within)
+	var expiring []namecheap.Domain
+	for _, d := range domains {
+		expires, err := d.ExpiresAt()
+		if err != nil {
+			log.Warnf("Cannot read the expiry date '%s' of '%s': %v", d.Expires, d.Name, err)
+			continue
+		}
+		if expires.After(deadline) || (skipAutoRenew && d.AutoRenew.Value) {
+			continue
+		}
+		expiring = append(expiring, d)
+	}
+
+	writeOutput(cmd, marshalDomains(format, expiring, now))
+	if len(expiring) > 0 {
+		log.Fatalf("%d of %d domains expire within %s", len(expiring), len(domains), config.ViperGetString(cmd, keyDomainsWithin))
+	}
+	log.Infof("None of %d domains expires within %s", len(domains), config.ViperGetString(cmd, keyDomainsWithin))
+}
+
+// domainColumns are the table and CSV columns of domain lists
+var domainColumns = []string{"NAME", "CREATED", "EXPIRES", "DAYS LEFT", "EXPIRED", "LOCKED", "AUTORENEW", "WHOISGUARD", "OURDNS"}
+
+// marshalDomains renders domains in the specified format
+func marshalDomains(format string, domains []namecheap.Domain, now time.Time) *[]byte {
+	rows := make([][]string, 0, len(domains))
+	for _, d := range domains {
+		rows = append(rows, []string{
+			d.Name, d.Created, d.Expires, daysLeft(d.Expires, now), d.IsExpired.String(), d.IsLocked.String(),
+			d.AutoRenew.String(), d.WhoisGuard, d.IsOurDNS.String(),
+		})
+	}
+	return marshalTable(format, domainColumns, rows, domains)
+}
+
+// marshalTable renders rows as a table or CSV, or v as JSON or YAML
+func marshalTable(format string, columns []string, rows [][]string, v interface{}) *[]byte {
+	var (
+		output []byte
+		err    error
+	)
+	switch format {
+	case domainsFormats[0]:
+		var b bytes.Buffer
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(columns, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		err = w.Flush()
+		output = bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	case domainsFormats[1]:
+		output, err = json.MarshalIndent(v, "", "  ")
+	case domainsFormats[2]:
+		output, err = yaml.Marshal(v)
+	case domainsFormats[3]:
+		var b bytes.Buffer
+		w := csv.NewWriter(&b)
+		_ = w.Write(columns)
+		_ = w.WriteAll(rows)
+		err = w.Error()
+		output = bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	}
+	if err != nil {
+		log.Fatalf("Failed to marshal format '%s': %s", format, err)
+	}
+	return &output
+}
+
+// daysLeft returns the whole days from now until an API date, negative when past, or '?' when the date is invalid
+func daysLeft(date string, now time.Time) string {
+	t, err := time.Parse(namecheap.DateLayout, date)
+	if err != nil {
+		return "?"
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return strconv.Itoa(int(t.Sub(today).Hours() / 24))
+}
+
+// parsePeriod parses a duration that may also be given in days or weeks, e.g.: '30d', '2w'
+func parsePeriod(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); strings.HasSuffix(s, suffix) && err == nil && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// lowerAll returns the values in lower case, for help texts
+func lowerAll(values []string) []string {
+	lower := make([]string, 0, len(values))
+	for _, v := range values {
+		lower = append(lower, strings.ToLower(v))
+	}
+	return lower
+}
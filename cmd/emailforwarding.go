@@ -0,0 +1,262 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyEmailMailbox   = "mailbox"
+	keyEmailForwardTo = "forward-to"
+)
+
+var (
+	requiredEmailForwardingFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld}
+
+	emailForwardingCmd = &cobra.Command{
+		Use:   "email-forwarding",
+		Short: "Manage the email forwarding of a domain",
+		Long: `Manage the email forwarding of a domain
+
+Forwarding only applies while the domain's email type is FWD, see 'setone --email-type'.`,
+		Aliases: []string{"ef"},
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	emailForwardingGetCmd = &cobra.Command{
+		Use:   "get",
+		Short: "Download the email forwarding of a domain",
+		Run:   RunEmailForwardingGet,
+	}
+
+	emailForwardingSetCmd = &cobra.Command{
+		Use:   "set",
+		Short: "Replace the email forwarding of a domain with the one from the input",
+		Run:   RunEmailForwardingSet,
+	}
+
+	emailForwardingAddCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Forward a mailbox to an address, keeping the other forwards",
+		Long:  `Forward a mailbox to an address, keeping the other forwards, e.g.: namecheap-cli email-forwarding add -s example -t com --mailbox info --forward-to me@example.net`,
+		Run:   RunEmailForwardingAdd,
+	}
+
+	emailForwardingRemoveCmd = &cobra.Command{
+		Use:   "remove",
+		Short: "Stop forwarding a mailbox, to one address or to all, keeping the other forwards",
+		Run:   RunEmailForwardingRemove,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(emailForwardingCmd)
+	emailForwardingCmd.AddCommand(emailForwardingGetCmd, emailForwardingSetCmd, emailForwardingAddCmd, emailForwardingRemoveCmd)
+
+	for _, c := range []*cobra.Command{emailForwardingGetCmd, emailForwardingSetCmd, emailForwardingAddCmd, emailForwardingRemoveCmd} {
+		addCommonFlags(c)
+		c.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
+		c.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
+		c.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+	}
+	emailForwardingSetCmd.Flags().Lookup(keyCommonTld).Usage = "Namecheap top-level domain, e.g.: 'com'. Can be read from the input file"
+	emailForwardingSetCmd.Flags().Lookup(keyCommonSld).Usage = "Namecheap second-level domain, e.g.: 'example'. Can be read from the input file"
+
+	emailForwardingGetCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+	emailForwardingGetCmd.Flags().String(keyGetOutputFormat, responseFormats[0], fmt.Sprintf("Output format. Supported: %v", responseFormats))
+	emailForwardingGetCmd.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+
+	emailForwardingSetCmd.Flags().StringP(keySetInputFile, "i", "", "Input file. If omitted, stdin is used until 2 consecutive newlines are detected")
+	emailForwardingSetCmd.Flags().String(keySetInputFormat, responseFormats[0], fmt.Sprintf("Input format. Supported: %v", responseFormats))
+	emailForwardingSetCmd.Flags().Bool(keyPlanDryRun, false, "Only show the changes that would be uploaded")
+	emailForwardingSetCmd.Flags().Bool(keyPlanAutoApprove, false, "Upload without asking for confirmation")
+
+	emailForwardingAddCmd.Flags().String(keyEmailMailbox, "", "[Required] Mailbox of the domain, the part before '@', e.g.: 'info'")
+	emailForwardingAddCmd.Flags().String(keyEmailForwardTo, "", "[Required] Address the mail is forwarded to")
+	emailForwardingRemoveCmd.Flags().String(keyEmailMailbox, "", "[Required] Mailbox of the domain, the part before '@', e.g.: 'info'")
+	emailForwardingRemoveCmd.Flags().String(keyEmailForwardTo, "", "Only stop forwarding to this address. If omitted, all forwards of the mailbox are removed")
+
+	for _, c := range []*cobra.Command{emailForwardingGetCmd, emailForwardingSetCmd, emailForwardingAddCmd, emailForwardingRemoveCmd} {
+		config.ViperBindPFlagSet(c, nil)
+	}
+}
+
+// RunEmailForwardingGet downloads the email forwarding and saves it in the specified format
+func RunEmailForwardingGet(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredEmailForwardingFlags)
+
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(responseFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, responseFormats)
+	}
+
+	response := downloadEmailForwarding(cmd)
+	output, err := encode(format, response)
+	if err != nil {
+		log.Fatalf("Failed to marshal format '%s': %s", format, err)
+	}
+	writeOutput(cmd, &output)
+}
+
+// RunEmailForwardingSet uploads the email forwarding from the input after showing the changes
+func RunEmailForwardingSet(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, []string{keyCommonUsername})
+
+	dryRun := config.ViperGetBool(cmd, keyPlanDryRun)
+	autoApprove := config.ViperGetBool(cmd, keyPlanAutoApprove)
+	if !dryRun && !autoApprove && len(config.ViperGetString(cmd, keySetInputFile)) == 0 {
+		log.Fatalf("Input is read from stdin, so changes cannot be confirmed interactively. Use --%s or --%s", keySetInputFile, keyPlanAutoApprove)
+	}
+
+	format := config.ViperGetString(cmd, keySetInputFormat)
+	if !slices.Contains(responseFormats, format) {
+		log.Fatalf("Input format '%s' is not supported. Please use one of: %v", format, responseFormats)
+	}
+	input := &namecheap.EmailForwardingResponse{}
+	if err := decode(format, *readInput(cmd), input); err != nil {
+		log.Fatalf("Failed to unmarshal: %s", err)
+	}
+	desired := input.CommandResponse.DomainDNSGetEmailForwardingResult.Forward
+	for _, f := range desired {
+		if err := f.Validate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// try to get tld and sld from input data
+	if len(config.ViperGetString(cmd, keyCommonSld)) == 0 || len(config.ViperGetString(cmd, keyCommonTld)) == 0 {
+		sld, tld, err := splitDomain(input.CommandResponse.DomainDNSGetEmailForwardingResult.Domain)
+		if err != nil {
+			log.Fatalf("Neither --%s and --%s were specified nor a valid domain was set in the input: %v", keyCommonSld, keyCommonTld, err)
+		}
+		config.ViperSet(cmd, keyCommonSld, sld)
+		config.ViperSet(cmd, keyCommonTld, tld)
+	}
+
+	current := downloadEmailForwarding(cmd).CommandResponse.DomainDNSGetEmailForwardingResult.Forward
+	added, removed := namecheap.DiffEmailForwarding(current, desired)
+	fmt.Println(formatForwardsDiff(added, removed))
+	if dryRun {
+		return
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		log.Info("No changes, nothing to upload")
+		return
+	}
+	if !autoApprove && !confirm("Do you want to upload these changes?") {
+		log.Info("Upload cancelled")
+		return
+	}
+
+	uploadEmailForwarding(cmd, desired)
+}
+
+// RunEmailForwardingAdd adds a single forward to the current ones
+func RunEmailForwardingAdd(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, append(requiredEmailForwardingFlags, keyEmailMailbox, keyEmailForwardTo))
+
+	forward := namecheap.EmailForward{
+		Mailbox:   config.ViperGetString(cmd, keyEmailMailbox),
+		ForwardTo: config.ViperGetString(cmd, keyEmailForwardTo),
+	}
+	if err := forward.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	forwards := downloadEmailForwarding(cmd).CommandResponse.DomainDNSGetEmailForwardingResult.Forward
+	for _, f := range forwards {
+		if f.Key() == forward.Key() {
+			log.Infof("'%s' already forwards to '%s'", forward.Mailbox, forward.ForwardTo)
+			return
+		}
+	}
+	uploadEmailForwarding(cmd, append(forwards, forward))
+}
+
+// RunEmailForwardingRemove removes the forwards of a mailbox, all or only the one to an address
+func RunEmailForwardingRemove(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, append(requiredEmailForwardingFlags, keyEmailMailbox))
+
+	mailbox := config.ViperGetString(cmd, keyEmailMailbox)
+	forwardTo := config.ViperGetString(cmd, keyEmailForwardTo)
+
+	current := downloadEmailForwarding(cmd).CommandResponse.DomainDNSGetEmailForwardingResult.Forward
+	kept := make([]namecheap.EmailForward, 0, len(current))
+	for _, f := range current {
+		if strings.EqualFold(f.Mailbox, mailbox) && (len(forwardTo) == 0 || strings.EqualFold(f.ForwardTo, forwardTo)) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if len(kept) == len(current) {
+		log.Infof("'%s' was already not forwarded", mailbox)
+		return
+	}
+	uploadEmailForwarding(cmd, kept)
+}
+
+// downloadEmailForwarding returns the current email forwarding of the domain
+func downloadEmailForwarding(cmd *cobra.Command) *namecheap.EmailForwardingResponse {
+	params := setCommonParameters(cmd)
+
+	log.Info("Downloading Namecheap email forwarding")
+
+	response, err := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout)).GetEmailForwarding(
+		context.Background(),
+		fmt.Sprintf("%s.%s", params.sld, params.tld),
+	)
+	if err != nil {
+		log.Fatalf("Failed to download email forwarding: %v", err)
+	}
+
+	log.Infof("Success. Execution time: %s", response.ExecutionTime)
+
+	return response
+}
+
+// uploadEmailForwarding replaces the email forwarding of the domain with forwards
+func uploadEmailForwarding(cmd *cobra.Command, forwards []namecheap.EmailForward) {
+	params := setCommonParameters(cmd)
+
+	log.Info("Uploading Namecheap email forwarding")
+
+	err := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout)).SetEmailForwarding(
+		context.Background(),
+		fmt.Sprintf("%s.%s", params.sld, params.tld),
+		forwards,
+	)
+	if err != nil {
+		log.Fatalf("Failed to upload email forwarding: %v", err)
+	}
+
+	log.Infof("Success. %d forwards are set", len(forwards))
+}
+
+// formatForwardsDiff returns one line per added (+) and removed (-) forward
+func formatForwardsDiff(added, removed []namecheap.EmailForward) string {
+	if len(added) == 0 && len(removed) == 0 {
+		return "No changes"
+	}
+	lines := make([]string, 0, len(added)+len(removed))
+	for _, f := range added {
+		lines = append(lines, fmt.Sprintf("+ %s -> %s", f.Mailbox, f.ForwardTo))
+	}
+	for _, f := range removed {
+		lines = append(lines, fmt.Sprintf("- %s -> %s", f.Mailbox, f.ForwardTo))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -4,10 +4,8 @@ Copyright © 2023 Dataflows
 package cmd
 
 import (
-	"encoding/xml"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"time"
 
 	"github.com/thedataflows/go-commons/pkg/config"
@@ -25,7 +23,7 @@ const (
 )
 
 var (
-	requiredGetFlags = []string{keyCommonApiKey, keyCommonUsername, keyCommonTld, keyCommonSld}
+	requiredGetFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld}
 
 	getCmd = &cobra.Command{
 		Use:     "get",
@@ -39,12 +37,9 @@ var (
 func init() {
 	rootCmd.AddCommand(getCmd)
 
-	getCmd.Flags().Bool(keyCommonSandbox, false, "Use Namecheap sandbox API")
-	getCmd.Flags().StringP(keyCommonApiKey, "k", "", "[Required] Namecheap API key")
-	getCmd.Flags().StringP(keyCommonUsername, "u", "", "[Required] Namecheap user")
+	addCommonFlags(getCmd)
 	getCmd.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
 	getCmd.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
-	getCmd.Flags().String(keyCommonClientIp, "127.0.0.1", "Client IP. This is not really required")
 
 	getCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
 	getCmd.Flags().String(keyGetOutputFormat, supportedFormats[0], fmt.Sprintf("Output format. Supported: %v", supportedFormats))
@@ -79,57 +74,16 @@ func download(cmd *cobra.Command, timeout time.Duration) *namecheap.ApiResponse
 
 	log.Info("Downloading Namecheap DNS configuration")
 
-	url := fmt.Sprintf(
-		namecheapApiUrl,
-		parentReqParams.sandbox,
-		parentReqParams.username,
-		parentReqParams.apiKey,
-		parentReqParams.username,
+	response, err := newClient(parentReqParams, time.Second*timeout).GetHosts(
+		context.Background(),
 		parentReqParams.sld,
 		parentReqParams.tld,
-		parentReqParams.clientIP,
-		"getHosts",
 	)
-	log.Debug(url)
-	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		log.Fatalf("Error sending request: %v", err)
+		log.Fatalf("Failed to download DNS configuration: %v", err)
 	}
-	req.Header.Set("Cache-Control", "no-cache")
+	checkNameservers(cmd, response)
 
-	// init & call
-	client := &http.Client{
-		Timeout: time.Second * timeout,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// read
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading body: %v", err)
-	}
-
-	// unmarshal
-	response := &namecheap.ApiResponse{}
-	err = xml.Unmarshal(body, response)
-	if err != nil {
-		log.Fatalf("Failed to unmarshal response body: %v", err)
-	}
-
-	// status check
-	if response.Status != "OK" {
-		messages := ""
-		for _, e := range response.Errors.Error {
-			messages = fmt.Sprintf("%s%s: %s\n", messages, e.Number, e.Text)
-		}
-		log.Fatalf("Received errors from the api server: \n%v", messages)
-	}
-
-	log.Debugf("Raw response: \n%s", string(body))
 	log.Infof("Success. Execution time: %s", response.ExecutionTime)
 
 	return response
@@ -0,0 +1,127 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/zalando/go-keyring"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyCommonApiKeyFile    = "key-file"
+	keyCommonApiKeyCommand = "key-command"
+	keyLoginDelete         = "delete"
+
+	// keyringService is the service name API keys are stored under in the OS keyring, one entry per user
+	keyringService = "namecheap-cli"
+)
+
+var (
+	requiredLoginFlags = []string{keyCommonUsername}
+
+	loginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Store the Namecheap API key in the OS keyring",
+		Long: `Store the Namecheap API key in the OS keyring
+
+The key is read from --key or from stdin, e.g.: 'pass show namecheap | namecheap-cli login -u myuser'.
+Other commands use it when none of --key, --key-file or --key-command is given.`,
+		Run: RunLogin,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().StringP(keyCommonUsername, "u", "", "[Required] Namecheap user")
+	loginCmd.Flags().StringP(keyCommonApiKey, "k", "", "Namecheap API key. If omitted, it is read from stdin")
+	loginCmd.Flags().Bool(keyLoginDelete, false, "Remove the stored API key instead")
+
+	config.ViperBindPFlagSet(loginCmd, nil)
+}
+
+// RunLogin stores or removes the API key of a user in the OS keyring
+func RunLogin(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredLoginFlags)
+
+	username := config.ViperGetString(cmd, keyCommonUsername)
+	if config.ViperGetBool(cmd, keyLoginDelete) {
+		if err := keyring.Delete(keyringService, username); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			log.Fatalf("Failed to remove the API key of '%s' from the OS keyring: %v", username, err)
+		}
+		log.Infof("Removed the API key of '%s' from the OS keyring", username)
+		return
+	}
+
+	apiKey := config.ViperGetString(cmd, keyCommonApiKey)
+	if len(apiKey) == 0 {
+		fmt.Fprint(os.Stderr, "Namecheap API key: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && len(line) == 0 {
+			log.Fatalf("Failed to read the API key from stdin: %v", err)
+		}
+		apiKey = strings.TrimSpace(line)
+	}
+	if len(apiKey) == 0 {
+		log.Fatalf("The API key is empty")
+	}
+	if err := keyring.Set(keyringService, username, apiKey); err != nil {
+		log.Fatalf("Failed to store the API key of '%s' in the OS keyring: %v", username, err)
+	}
+	log.Infof("Stored the API key of '%s' in the OS keyring", username)
+}
+
+// resolveApiKey returns the API key from the first source set: --key, --key-file, --key-command, then the OS keyring.
+// It returns an empty string when there is none
+func resolveApiKey(cmd *cobra.Command, username string) string {
+	if apiKey := config.ViperGetString(cmd, keyCommonApiKey); len(apiKey) > 0 {
+		return apiKey
+	}
+
+	if path := config.ViperGetString(cmd, keyCommonApiKeyFile); len(path) > 0 {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			log.Fatalf("Failed to read the API key from --%s: %v", keyCommonApiKeyFile, err)
+		}
+		return strings.TrimSpace(string(data))
+	}
+
+	if command := config.ViperGetString(cmd, keyCommonApiKeyCommand); len(command) > 0 {
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		c := exec.Command(shell, flag, command) // #nosec G204 -- the command is configured by the user on purpose
+		c.Stderr = os.Stderr
+		out, err := c.Output()
+		if err != nil {
+			log.Fatalf("Failed to get the API key from --%s: %v", keyCommonApiKeyCommand, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if len(username) == 0 {
+		return ""
+	}
+	apiKey, err := keyring.Get(keyringService, username)
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			log.Debugf("Failed to read the API key of '%s' from the OS keyring: %v", username, err)
+		}
+		return ""
+	}
+	return apiKey
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/mockserver"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyMockListen    = "listen"
+	keyMockDomains   = "domains"
+	keyMockRateLimit = "rate-limit"
+)
+
+var (
+	mockServerCmd = &cobra.Command{
+		Use:   "mock-server",
+		Short: "Run a local fake Namecheap API keeping DNS configuration in memory",
+		Long: `Run a local fake Namecheap API keeping DNS configuration in memory
+
+Point other commands to it with --api-url, e.g.: namecheap-cli get --api-url http://127.0.0.1:8080/xml.response ...`,
+		Run: RunMockServer,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+
+	mockServerCmd.Flags().String(keyMockListen, "127.0.0.1:8080", "Address to listen on")
+	mockServerCmd.Flags().StringP(keyCommonApiKey, "k", "", "Only accept this API key. If omitted, any key is accepted")
+	mockServerCmd.Flags().StringP(keyCommonUsername, "u", "", "Only accept this API user, together with --key")
+	mockServerCmd.Flags().StringP(keySetInputFile, "i", "", "Seed the server with the domain and hosts from this file")
+	mockServerCmd.Flags().String(keySetInputFormat, supportedFormats[0], fmt.Sprintf("Input format. Supported: %v", supportedFormats))
+	mockServerCmd.Flags().String(keyMockDomains, "", "Comma separated domains to create without any hosts, e.g.: 'example.com,example.net'")
+	mockServerCmd.Flags().Int(keyMockRateLimit, 20, "Requests per minute above which 'too many requests' errors are returned. If 0, unlimited")
+
+	config.ViperBindPFlagSet(mockServerCmd, nil)
+}
+
+// RunMockServer serves the fake API until the process is stopped
+func RunMockServer(cmd *cobra.Command, args []string) {
+	rateLimit, err := strconv.Atoi(config.ViperGetString(cmd, keyMockRateLimit))
+	if err != nil {
+		log.Fatalf("Invalid --%s: %v", keyMockRateLimit, err)
+	}
+	server := mockserver.New(
+		mockserver.WithCredentials(
+			config.ViperGetString(cmd, keyCommonUsername),
+			config.ViperGetString(cmd, keyCommonApiKey),
+		),
+		mockserver.WithRateLimit(rateLimit),
+	)
+
+	for _, domain := range splitList(config.ViperGetString(cmd, keyMockDomains)) {
+		server.AddDomain(domain, "MX")
+	}
+	if len(config.ViperGetString(cmd, keySetInputFile)) > 0 {
+		format := config.ViperGetString(cmd, keySetInputFormat)
+		if !slices.Contains(supportedFormats, format) {
+			log.Fatalf("Input format '%s' is not supported. Please use one of: %v", format, supportedFormats)
+		}
+		input := unmarshal(format, readInput(cmd)).CommandResponse.DomainDNSGetHostsResult
+		if len(input.Domain) == 0 {
+			log.Fatalf("The input file has no domain")
+		}
+		server.AddDomain(input.Domain, input.EmailType, input.Host...)
+		log.Infof("Seeded '%s' with %d hosts", input.Domain, len(input.Host))
+	}
+
+	listen := config.ViperGetString(cmd, keyMockListen)
+	log.Infof("Mock Namecheap API listening on http://%s/xml.response", listen)
+	httpServer := &http.Server{
+		Addr:              listen,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyAllowCustomNameservers = "allow-custom-nameservers"
+)
+
+var (
+	requiredNameserversFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld}
+
+	nameserversCmd = &cobra.Command{
+		Use:   "nameservers",
+		Short: "Switch domains between Namecheap DNS and custom nameservers",
+		Long: `Switch domains between Namecheap DNS and custom nameservers
+
+Host records managed by 'get', 'set' and the other commands only have an effect while the domain uses Namecheap DNS.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	nameserversGetCmd = &cobra.Command{
+		Use:   "get",
+		Short: "Show the nameservers of a domain and whether they are Namecheap's",
+		Run:   RunNameserversGet,
+	}
+
+	nameserversSetDefaultCmd = &cobra.Command{
+		Use:   "set-default",
+		Short: "Switch a domain back to Namecheap BasicDNS, so its host records apply",
+		Run:   RunNameserversSetDefault,
+	}
+
+	nameserversSetCustomCmd = &cobra.Command{
+		Use:   "set-custom <nameserver>...",
+		Short: "Delegate a domain to external nameservers",
+		Long: `Delegate a domain to external nameservers, e.g.: namecheap-cli nameservers set-custom -s example -t com ns1.example.net ns2.example.net
+
+Host records kept at Namecheap stop having any effect.`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  RunNameserversSetCustom,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(nameserversCmd)
+	nameserversCmd.AddCommand(nameserversGetCmd, nameserversSetDefaultCmd, nameserversSetCustomCmd)
+
+	for _, c := range []*cobra.Command{nameserversGetCmd, nameserversSetDefaultCmd, nameserversSetCustomCmd} {
+		addCommonFlags(c)
+		c.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
+		c.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
+		c.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+	}
+	nameserversGetCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+	nameserversGetCmd.Flags().String(keyGetOutputFormat, domainsFormats[0], fmt.Sprintf("Output format. Supported: %v", domainsFormats))
+	nameserversGetCmd.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+	nameserversSetCustomCmd.Flags().Bool(keyPlanAutoApprove, false, "Switch without asking for confirmation")
+
+	config.ViperBindPFlagSet(nameserversGetCmd, nil)
+	config.ViperBindPFlagSet(nameserversSetDefaultCmd, nil)
+	config.ViperBindPFlagSet(nameserversSetCustomCmd, nil)
+}
+
+// RunNameserversGet outputs the nameservers of the domain
+func RunNameserversGet(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredNameserversFlags)
+
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(domainsFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, domainsFormats)
+	}
+
+	params := setCommonParameters(cmd)
+	nameservers, err := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout)).GetNameservers(
+		context.Background(),
+		params.sld,
+		params.tld,
+	)
+	if err != nil {
+		log.Fatalf("Failed to get the nameservers: %v", err)
+	}
+
+	rows := [][]string{{nameservers.Domain, nameservers.IsUsingOurDNS.String(), strings.Join(nameservers.Nameserver, " ")}}
+	writeOutput(cmd, marshalTable(format, []string{"DOMAIN", "OUR DNS", "NAMESERVERS"}, rows, nameservers))
+}
+
+// RunNameserversSetDefault switches the domain to Namecheap BasicDNS
+func RunNameserversSetDefault(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredNameserversFlags)
+
+	params := setCommonParameters(cmd)
+	err := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout)).SetDefaultNameservers(
+		context.Background(),
+		params.sld,
+		params.tld,
+	)
+	if err != nil {
+		log.Fatalf("Failed to switch to Namecheap DNS: %v", err)
+	}
+	log.Infof("'%s.%s' uses Namecheap DNS", params.sld, params.tld)
+}
+
+// RunNameserversSetCustom delegates the domain to the nameservers given as arguments
+func RunNameserversSetCustom(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredNameserversFlags)
+
+	params := setCommonParameters(cmd)
+	question := fmt.Sprintf("Host records of '%s.%s' will stop having any effect. Do you want to delegate it to %v?", params.sld, params.tld, args)
+	if !config.ViperGetBool(cmd, keyPlanAutoApprove) && !confirm(question) {
+		log.Info("Switch cancelled")
+		return
+	}
+
+	err := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout)).SetCustomNameservers(
+		context.Background(),
+		params.sld,
+		params.tld,
+		args,
+	)
+	if err != nil {
+		log.Fatalf("Failed to set custom nameservers: %v", err)
+	}
+	log.Infof("'%s.%s' uses nameservers %v", params.sld, params.tld, args)
+}
+
+// addNameserversFlags adds the flag allowing uploads of host records that have no effect
+func addNameserversFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(keyAllowCustomNameservers, false, "Upload host records even when the domain uses custom nameservers, where they have no effect")
+}
+
+// checkNameservers warns when the domain of response uses custom nameservers. Commands uploading host records,
+// the ones having the --allow-custom-nameservers flag, exit instead unless it is set or it is a dry run
+func checkNameservers(cmd *cobra.Command, response *namecheap.ApiResponse) {
+	if response.UsesOurDNS() {
+		return
+	}
+	domain := response.CommandResponse.DomainDNSGetHostsResult.Domain
+	if !refuseCustomNameservers(cmd) || (cmd.Flags().Lookup(keyPlanDryRun) != nil && config.ViperGetBool(cmd, keyPlanDryRun)) {
+		log.Warnf("'%s' uses custom nameservers, so its host records have no effect", domain)
+		return
+	}
+	log.Fatalf("'%s' uses custom nameservers, so uploaded host records would have no effect. Switch with 'nameservers set-default' or use --%s", domain, keyAllowCustomNameservers)
+}
+
+// refuseCustomNameservers reports whether cmd must not upload host records of domains using custom nameservers
+func refuseCustomNameservers(cmd *cobra.Command) bool {
+	return cmd.Flags().Lookup(keyAllowCustomNameservers) != nil && !config.ViperGetBool(cmd, keyAllowCustomNameservers)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"gopkg.in/yaml.v3"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyPlanDryRun      = "dry-run"
+	keyPlanAutoApprove = "auto-approve"
+)
+
+var (
+	planFormats = []string{"text", "yaml", "json"}
+
+	planCmd = &cobra.Command{
+		Use:     "plan",
+		Short:   "Show the DNS record changes 'set' would upload",
+		Long:    ``,
+		Aliases: []string{"p"},
+		Run:     RunPlan,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	addCommonFlags(planCmd)
+	planCmd.Flags().StringP(keyCommonTld, "t", "", "Namecheap top-level domain, e.g.: 'com'. Can be read from the input file")
+	planCmd.Flags().StringP(keyCommonSld, "s", "", "Namecheap second-level domain, e.g.: 'example'. Can be read from the input file")
+
+	planCmd.Flags().StringP(keySetInputFile, "i", "", "Input file. If omitted, stdin is used until 2 consecutive newlines are detected")
+	planCmd.Flags().String(keySetInputFormat, supportedFormats[0], fmt.Sprintf("Input format. Supported: %v", supportedFormats))
+	planCmd.Flags().StringP(keyGetOutputFile, "o", "", "Output file. If omitted, outputs to stdout")
+	planCmd.Flags().String(keyGetOutputFormat, planFormats[0], fmt.Sprintf("Output format. Supported: %v", planFormats))
+	planCmd.Flags().Bool(keyConvertForce, false, "Force overwriting the file if exists")
+	planCmd.Flags().Duration(keySetTimeout, 10, "Request timeout")
+
+	config.ViperBindPFlagSet(planCmd, nil)
+}
+
+// RunPlan downloads the current Namecheap DNS configuration and outputs the differences to the input
+func RunPlan(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredSetFlags)
+
+	format := config.ViperGetString(cmd, keyGetOutputFormat)
+	if !slices.Contains(planFormats, format) {
+		log.Fatalf("Output format '%s' is not supported. Please use one of: %v", format, planFormats)
+	}
+
+	input := readSetInput(cmd)
+	diff := planHosts(cmd, input, config.ViperGetDuration(cmd, keySetTimeout))
+
+	writeOutput(cmd, marshalPlan(format, diff))
+}
+
+// planHosts downloads the current DNS configuration and diffs it against the hosts that would be uploaded
+func planHosts(cmd *cobra.Command, input *namecheap.ApiResponse, timeout time.Duration) *namecheap.HostsDiff {
+	current := download(cmd, timeout)
+
+	desired := make([]namecheap.Host, 0, len(input.CommandResponse.DomainDNSGetHostsResult.Host))
+	for _, host := range input.CommandResponse.DomainDNSGetHostsResult.Host {
+		// hosts without id are not uploaded
+		if len(host.HostId) > 0 {
+			desired = append(desired, host)
+		}
+	}
+
+	diff := namecheap.DiffHosts(current.CommandResponse.DomainDNSGetHostsResult.Host, desired)
+	diff.EmailType = namecheap.DiffEmailType(
+		current.CommandResponse.DomainDNSGetHostsResult.EmailType,
+		input.CommandResponse.DomainDNSGetHostsResult.EmailType,
+	)
+	return diff
+}
+
+// marshalPlan renders the differences in the specified format
+func marshalPlan(format string, diff *namecheap.HostsDiff) *[]byte {
+	var (
+		output []byte
+		err    error
+	)
+	switch format {
+	case planFormats[0]:
+		output = []byte(diff.String())
+	case planFormats[1]:
+		output, err = yaml.Marshal(diff)
+	case planFormats[2]:
+		output, err = json.MarshalIndent(diff, "", "  ")
+	}
+	if err != nil {
+		log.Fatalf("Failed to marshal format '%s': %s", format, err)
+	}
+	return &output
+}
+
+// confirm asks a yes/no question on stdin
+func confirm(question string) bool {
+	fmt.Printf("%s Only 'yes' will be accepted: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(answer) == "yes"
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/backup"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyBackupDir        = "backup-dir"
+	keyRollbackList     = "list"
+	keyRollbackSnapshot = "snapshot"
+)
+
+var (
+	requiredRollbackFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld}
+
+	rollbackCmd = &cobra.Command{
+		Use:   "rollback",
+		Short: "List the automatic backups of a domain or upload one of them back",
+		Long: `List the automatic backups of a domain or upload one of them back
+
+Every command uploading DNS configuration first saves the current one to --backup-dir.
+The differences to the chosen snapshot are shown before uploading it.`,
+		Aliases: []string{"r"},
+		Run:     RunRollback,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	addCommonFlags(rollbackCmd)
+	rollbackCmd.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
+	rollbackCmd.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
+	addBackupFlags(rollbackCmd)
+
+	rollbackCmd.Flags().BoolP(keyRollbackList, "l", false, "Only list the snapshots, newest first")
+	rollbackCmd.Flags().String(keyRollbackSnapshot, "1", "Snapshot to upload: its number in --list, or a file path")
+	rollbackCmd.Flags().Bool(keyPlanAutoApprove, false, "Upload without asking for confirmation")
+	rollbackCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+
+	config.ViperBindPFlagSet(rollbackCmd, nil)
+}
+
+// RunRollback lists the snapshots of a domain or uploads the chosen one after showing the differences
+func RunRollback(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredRollbackFlags)
+
+	params := setCommonParameters(cmd)
+	domain := fmt.Sprintf("%s.%s", params.sld, params.tld)
+	dir := config.ViperGetString(cmd, keyBackupDir)
+	if len(dir) == 0 {
+		dir = backup.DefaultDir()
+	}
+	snapshots, err := backup.List(dir, domain)
+	if err != nil {
+		log.Fatalf("Failed to list snapshots of '%s': %v", domain, err)
+	}
+
+	if config.ViperGetBool(cmd, keyRollbackList) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tTIME\tHOSTS\tPATH")
+		for i, s := range snapshots {
+			hosts := "?"
+			if snapshot, err := backup.Load(s.Path); err == nil {
+				hosts = strconv.Itoa(len(snapshot.CommandResponse.DomainDNSGetHostsResult.Host))
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, s.Time.Local().Format(time.RFC3339), hosts, s.Path)
+		}
+		_ = w.Flush()
+		return
+	}
+
+	path := config.ViperGetString(cmd, keyRollbackSnapshot)
+	if n, err := strconv.Atoi(path); err == nil {
+		if n < 1 || n > len(snapshots) {
+			log.Fatalf("There is no snapshot %d of '%s' in '%s', found %d", n, domain, dir, len(snapshots))
+		}
+		path = snapshots[n-1].Path
+	}
+	snapshot, err := backup.Load(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if snapshotDomain := snapshot.CommandResponse.DomainDNSGetHostsResult.Domain; !strings.EqualFold(snapshotDomain, domain) {
+		log.Fatalf("Snapshot '%s' is of '%s', not '%s'", path, snapshotDomain, domain)
+	}
+	log.Infof("Rolling back '%s' to '%s'", domain, path)
+
+	timeout := config.ViperGetDuration(cmd, keyGetTimeout)
+	diff := planHosts(cmd, snapshot, timeout)
+	fmt.Println(diff)
+	if diff.IsEmpty() {
+		log.Info("No changes, nothing to upload")
+		return
+	}
+	if !config.ViperGetBool(cmd, keyPlanAutoApprove) && !confirm("Do you want to upload this snapshot?") {
+		log.Info("Rollback cancelled")
+		return
+	}
+
+	upload(cmd, snapshot, timeout)
+}
+
+// addBackupFlags adds the flags of commands saving a backup before uploading
+func addBackupFlags(cmd *cobra.Command) {
+	cmd.Flags().String(keyBackupDir, backup.DefaultDir(), "Directory where the current DNS configuration is saved before every upload. If empty, no backup is made")
+}
+
+// backupCurrent saves the DNS configuration currently live to the backup directory, if any
+func backupCurrent(ctx context.Context, cmd *cobra.Command, client *namecheap.Client, params *requestParameters, current *namecheap.ApiResponse) {
+	dir := config.ViperGetString(cmd, keyBackupDir)
+	if len(dir) == 0 {
+		log.Debug("Backup disabled")
+		return
+	}
+
+	if current == nil {
+		var err error
+		current, err = client.GetHosts(ctx, params.sld, params.tld)
+		if err != nil {
+			log.Fatalf("Failed to download DNS configuration for backup, not uploading: %v", err)
+		}
+	}
+	path, err := backup.Save(dir, current, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to save backup, not uploading. Use --%s='' to skip it: %v", keyBackupDir, err)
+	}
+	log.Infof("Saved backup to '%s'", path)
+}
@@ -6,20 +6,29 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
 	"github.com/thedataflows/namecheap-cli/pkg/constants"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
 
 	"github.com/spf13/cobra"
 )
 
 type requestParameters struct {
-	sandbox  string
+	sandbox  bool
 	apiKey   string
 	username string
 	tld      string
 	sld      string
 	clientIP string
+	apiURL   string
+	retry    namecheap.RetryPolicy
+	limits   []namecheap.Limit
 }
 
 const (
@@ -29,11 +38,17 @@ const (
 	keyCommonTld      = "tld"
 	keyCommonSld      = "sld"
 	keyCommonClientIp = "client-ip"
-	namecheapApiUrl   = "https://api.%snamecheap.com/xml.response?apiuser=%s&apikey=%s&username=%s&SLD=%s&TLD=%s&ClientIP=%s&Command=namecheap.domains.dns.%s"
+	keyCommonApiUrl   = "api-url"
+
+	keyCommonRetries    = "retries"
+	keyCommonRetryDelay = "retry-delay"
+	keyCommonRateLimit  = "rate-limit"
 )
 
 var (
-	supportedFormats = []string{"xml", "yaml", "json"}
+	supportedFormats = []string{"xml", "yaml", "json", "bind", "zone"}
+	// responseFormats are the formats of API responses other than host records, which zone formats cannot hold
+	responseFormats = supportedFormats[:3]
 
 	// rootCmd represents the base command when called without any subcommands
 	rootCmd = &cobra.Command{
@@ -50,6 +65,10 @@ var (
 		},
 	}
 
+	// apiLimiter is shared by all clients of the process, so the rate limits hold for commands calling the API many times
+	apiLimiter     *namecheap.RateLimiter
+	apiLimiterOnce sync.Once
+
 	configOpts = config.DefaultConfigOpts(
 		&config.Opts{
 			EnvPrefix: constants.ViperEnvPrefix,
@@ -58,18 +77,84 @@ var (
 )
 
 func setCommonParameters(cmd *cobra.Command) *requestParameters {
-	s := ""
-	if config.ViperGetBool(cmd, keyCommonSandbox) {
-		s = "sandbox."
-	}
-	return &requestParameters{
-		sandbox:  s,
-		apiKey:   config.ViperGetString(cmd, keyCommonApiKey),
-		username: config.ViperGetString(cmd, keyCommonUsername),
+	username := config.ViperGetString(cmd, keyCommonUsername)
+	params := &requestParameters{
+		sandbox:  config.ViperGetBool(cmd, keyCommonSandbox),
+		apiKey:   resolveApiKey(cmd, username),
+		username: username,
 		sld:      config.ViperGetString(cmd, keyCommonSld),
 		tld:      config.ViperGetString(cmd, keyCommonTld),
 		clientIP: config.ViperGetString(cmd, keyCommonClientIp),
+		apiURL:   config.ViperGetString(cmd, keyCommonApiUrl),
+		retry:    namecheap.DefaultRetryPolicy,
+		limits:   namecheap.DefaultLimits,
+	}
+
+	if retries := config.ViperGetString(cmd, keyCommonRetries); len(retries) > 0 {
+		var err error
+		if params.retry.Retries, err = strconv.Atoi(retries); err != nil || params.retry.Retries < 0 {
+			log.Fatalf("--%s must be zero or a positive number", keyCommonRetries)
+		}
+	}
+	if delay := config.ViperGetDuration(cmd, keyCommonRetryDelay); delay > 0 {
+		params.retry.MinDelay = delay
+	}
+	if cmd.Flags().Lookup(keyCommonRateLimit) != nil {
+		var err error
+		if params.limits, err = namecheap.ParseLimits(config.ViperGetString(cmd, keyCommonRateLimit)); err != nil {
+			log.Fatalf("Invalid --%s: %v", keyCommonRateLimit, err)
+		}
+	}
+	return params
+}
+
+// addCommonFlags adds the credential and endpoint flags shared by all commands calling the API
+func addCommonFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(keyCommonSandbox, false, "Use Namecheap sandbox API")
+	cmd.Flags().StringP(keyCommonApiKey, "k", "", "Namecheap API key. Prefer --key-file, --key-command or 'login' to keep it out of the environment and shell history")
+	cmd.Flags().String(keyCommonApiKeyFile, "", "Read the Namecheap API key from this file")
+	cmd.Flags().String(keyCommonApiKeyCommand, "", "Read the Namecheap API key from the output of this shell command, e.g.: 'pass show namecheap'")
+	cmd.Flags().StringP(keyCommonUsername, "u", "", "[Required] Namecheap user")
+	cmd.Flags().String(keyCommonClientIp, namecheap.DefaultClientIP, "Client IP. This is not really required")
+	cmd.Flags().String(keyCommonApiUrl, "", "Override the Namecheap API endpoint, e.g.: a local 'mock-server'. Takes precedence over --sandbox")
+	cmd.Flags().Int(keyCommonRetries, namecheap.DefaultRetryPolicy.Retries, "Retries of requests failing with network errors, server errors or 'too many requests'. If 0, requests are not retried")
+	cmd.Flags().Duration(keyCommonRetryDelay, namecheap.DefaultRetryPolicy.MinDelay, "Delay before the first retry, doubled for each following one, with random jitter")
+	cmd.Flags().String(keyCommonRateLimit, "20/m,700/h,8000/d", "Client side API rate limits, Namecheap's documented ones by default. If empty, unlimited")
+}
+
+// newClient returns a Namecheap API client configured from the common parameters and any extra options
+func newClient(params *requestParameters, timeout time.Duration, opts ...namecheap.Option) *namecheap.Client {
+	if len(params.apiKey) == 0 {
+		log.Fatalf("Namecheap API key is required: use --%s, --%s, --%s or store it with 'login'", keyCommonApiKey, keyCommonApiKeyFile, keyCommonApiKeyCommand)
+	}
+	apiLimiterOnce.Do(func() {
+		apiLimiter = namecheap.NewRateLimiter(params.limits...)
+	})
+	client, err := namecheap.NewClient(
+		append([]namecheap.Option{
+			namecheap.WithCredentials(params.username, params.apiKey),
+			namecheap.WithSandbox(params.sandbox),
+			namecheap.WithClientIP(params.clientIP),
+			namecheap.WithBaseURL(params.apiURL),
+			namecheap.WithTimeout(timeout),
+			namecheap.WithRetryPolicy(params.retry),
+			namecheap.WithRateLimiter(apiLimiter),
+			namecheap.WithDebugLogger(log.Debugf),
+		}, opts...)...,
+	)
+	if err != nil {
+		log.Fatalf("Failed to create Namecheap client: %v", err)
+	}
+	return client
+}
+
+// splitDomain splits a registered domain into its second-level label and the rest, e.g.: 'example.co.uk' into 'example' and 'co.uk'
+func splitDomain(domain string) (string, string, error) {
+	sld, tld, found := strings.Cut(strings.TrimSuffix(domain, "."), ".")
+	if !found || len(sld) == 0 || len(tld) == 0 {
+		return "", "", fmt.Errorf("'%s' is not a domain name", domain)
 	}
+	return sld, tld, nil
 }
 
 func initConfig() {
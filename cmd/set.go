@@ -4,17 +4,13 @@ Copyright © 2023 Dataflows
 package cmd
 
 import (
-	"bytes"
-	"encoding/xml"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/thedataflows/go-commons/pkg/config"
 	"github.com/thedataflows/go-commons/pkg/log"
-	"github.com/thedataflows/go-commons/pkg/stringutil"
 	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
 	"k8s.io/utils/strings/slices"
 
@@ -28,7 +24,7 @@ const (
 )
 
 var (
-	requiredSetFlags = []string{keyCommonApiKey, keyCommonUsername}
+	requiredSetFlags = []string{keyCommonUsername}
 
 	setCmd = &cobra.Command{
 		Use:     "set",
@@ -42,25 +38,55 @@ var (
 func init() {
 	rootCmd.AddCommand(setCmd)
 
-	setCmd.Flags().Bool(keyCommonSandbox, false, "Use Namecheap sandbox API")
-	setCmd.Flags().StringP(keyCommonApiKey, "k", "", "[Required] Namecheap API key")
-	setCmd.Flags().StringP(keyCommonUsername, "u", "", "[Required] Namecheap user")
+	addCommonFlags(setCmd)
 	setCmd.Flags().StringP(keyCommonTld, "t", "", "Namecheap top-level domain, e.g.: 'com'. Can be read from the input file")
 	setCmd.Flags().StringP(keyCommonSld, "s", "", "Namecheap second-level domain, e.g.: 'example'. Can be read from the input file")
-	setCmd.Flags().String(keyCommonClientIp, "127.0.0.1", "Client IP. This is not really required")
 
 	setCmd.Flags().StringP(keySetInputFile, "i", "", "Input file. If omitted, stdin is used until 2 consecutive newlines are detected")
 	setCmd.Flags().String(keySetInputFormat, supportedFormats[0], fmt.Sprintf("Input format. Supported: %v", supportedFormats))
 	setCmd.Flags().Duration(keySetTimeout, 10, "Request timeout")
+	setCmd.Flags().Bool(keyPlanDryRun, false, "Only show the changes that would be uploaded")
+	setCmd.Flags().Bool(keyPlanAutoApprove, false, "Upload without asking for confirmation")
+	addBackupFlags(setCmd)
+	addNameserversFlags(setCmd)
 
 	config.ViperBindPFlagSet(setCmd, nil)
 }
 
-// RunSet uploads the Namecheap DNS configuration
+// RunSet uploads the Namecheap DNS configuration after showing the planned changes
 func RunSet(cmd *cobra.Command, args []string) {
 	// Validations
 	config.CheckRequiredFlags(cmd, requiredSetFlags)
 
+	dryRun := config.ViperGetBool(cmd, keyPlanDryRun)
+	autoApprove := config.ViperGetBool(cmd, keyPlanAutoApprove)
+	if !dryRun && !autoApprove && len(config.ViperGetString(cmd, keySetInputFile)) == 0 {
+		log.Fatalf("Input is read from stdin, so changes cannot be confirmed interactively. Use --%s or --%s", keySetInputFile, keyPlanAutoApprove)
+	}
+
+	input := readSetInput(cmd)
+	validateHosts(input.CommandResponse.DomainDNSGetHostsResult.Host)
+	timeout := config.ViperGetDuration(cmd, keySetTimeout)
+
+	diff := planHosts(cmd, input, timeout)
+	fmt.Println(diff)
+	if dryRun {
+		return
+	}
+	if diff.IsEmpty() {
+		log.Info("No changes, nothing to upload")
+		return
+	}
+	if !autoApprove && !confirm("Do you want to upload these changes?") {
+		log.Info("Upload cancelled")
+		return
+	}
+
+	upload(cmd, input, timeout)
+}
+
+// readSetInput reads the input configuration and fills in sld and tld from it when not specified
+func readSetInput(cmd *cobra.Command) *namecheap.ApiResponse {
 	format := config.ViperGetString(cmd, keySetInputFormat)
 	if !slices.Contains(supportedFormats, format) {
 		log.Fatalf("Input format '%s' is not supported. Please use one of: %v", format, supportedFormats)
@@ -85,78 +111,30 @@ func RunSet(cmd *cobra.Command, args []string) {
 		config.ViperSet(cmd, keyCommonTld, domainSegments[1])
 	}
 
-	upload(cmd, input, config.ViperGetDuration(cmd, keySetTimeout))
+	return input
 }
 
-// upload performs a POST request on the Namecheap API endpoint with the
+// upload performs a POST request on the Namecheap API endpoint with the hosts and email type from input,
+// after saving the current configuration to the backup directory
 func upload(cmd *cobra.Command, input *namecheap.ApiResponse, timeout time.Duration) {
 	parentReqParams := setCommonParameters(cmd)
+	client := newClient(parentReqParams, time.Second*timeout)
+	ctx := context.Background()
+
+	backupCurrent(ctx, cmd, client, parentReqParams, nil)
 
 	log.Info("Uploading Namecheap DNS configuration")
 
-	url := fmt.Sprintf(
-		namecheapApiUrl,
-		parentReqParams.sandbox,
-		parentReqParams.username,
-		parentReqParams.apiKey,
-		parentReqParams.username,
+	response, err := client.SetHosts(
+		ctx,
 		parentReqParams.sld,
 		parentReqParams.tld,
-		parentReqParams.clientIP,
-		"setHosts",
+		input.CommandResponse.DomainDNSGetHostsResult.Host,
+		input.CommandResponse.DomainDNSGetHostsResult.EmailType,
 	)
-	log.Debug(url)
-
-	requestBody := ""
-	for _, host := range input.CommandResponse.DomainDNSGetHostsResult.Host {
-		if len(host.HostId) > 0 {
-			requestBody = stringutil.ConcatStrings(
-				requestBody,
-				"HostName", host.HostId, "=", host.Name, "&",
-				"RecordType", host.HostId, "=", host.Type, "&",
-				"Address", host.HostId, "=", host.Address, "&",
-				"MXPref", host.HostId, "=", host.MXPref, "&",
-				"TTL", host.HostId, "=", host.TTL, "&",
-				"FriendlyName", host.HostId, "=", host.FriendlyName, "&",
-				"IsActive", host.HostId, "=", host.IsActive, "&",
-			)
-		}
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(requestBody)))
 	if err != nil {
-		log.Fatalf("Error sending request: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	client := &http.Client{
-		Timeout: time.Second * timeout,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("Error reading response: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading body: %v", err)
-	}
-
-	response := &namecheap.ApiResponse{}
-	err = xml.Unmarshal(body, response)
-	if err != nil {
-		log.Fatalf("Failed to unmarshal response body: %v", err)
-	}
-
-	if response.Status != "OK" {
-		messages := ""
-		for _, e := range response.Errors.Error {
-			messages = fmt.Sprintf("%s%s: %s", messages, e.Number, e.Text)
-		}
-		log.Fatalf("Received errors from the api server: \n%v", messages)
+		log.Fatalf("Failed to upload DNS configuration: %v", err)
 	}
 
-	log.Debugf("Raw response: \n%s", string(body))
 	log.Infof("Success. Execution time: %s", response.ExecutionTime)
 }
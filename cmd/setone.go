@@ -4,6 +4,10 @@ Copyright © 2023 Dataflows
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/thedataflows/go-commons/pkg/config"
 	"github.com/thedataflows/go-commons/pkg/log"
 	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
@@ -20,10 +24,16 @@ const (
 	setOneKeyFriendlyName = "friendlyname"
 	setOneKeyIsActive     = "isactive"
 	setOneKeyDelete       = "delete"
+	setOneKeyAdd          = "add"
+	setOneKeyReplaceAll   = "replace-all"
+	setOneKeyMatchAddress = "match-address"
+	setOneKeyEmailType    = "email-type"
+	setOneKeyFlag         = "flag"
+	setOneKeyTag          = "tag"
 )
 
 var (
-	requiredSetOneFlags = []string{keyCommonApiKey, keyCommonUsername, keyCommonTld, keyCommonSld, setOneKeyName, setOneKeyType, setOneKeyAddress}
+	requiredSetOneFlags = []string{keyCommonUsername, keyCommonTld, keyCommonSld, setOneKeyName, setOneKeyType, setOneKeyAddress}
 
 	setOneCmd = &cobra.Command{
 		Use:     "setone",
@@ -37,12 +47,9 @@ var (
 func init() {
 	rootCmd.AddCommand(setOneCmd)
 
-	setOneCmd.Flags().Bool(keyCommonSandbox, false, "Use Namecheap sandbox API")
-	setOneCmd.Flags().StringP(keyCommonApiKey, "k", "", "[Required] Namecheap API key")
-	setOneCmd.Flags().StringP(keyCommonUsername, "u", "", "[Required] Namecheap user")
+	addCommonFlags(setOneCmd)
 	setOneCmd.Flags().StringP(keyCommonTld, "t", "", "[Required] Namecheap top-level domain, e.g.: 'com'")
 	setOneCmd.Flags().StringP(keyCommonSld, "s", "", "[Required] Namecheap second-level domain, e.g.: 'example'")
-	setOneCmd.Flags().String(keyCommonClientIp, "127.0.0.1", "Client IP. This is not really required")
 
 	setOneCmd.Flags().String(setOneKeyName, "", "[Required] Record name")
 	setOneCmd.Flags().String(setOneKeyType, "", "[Required] Record type")
@@ -51,10 +58,18 @@ func init() {
 	setOneCmd.Flags().String(setOneKeyTTL, "1799", "Time to live in seconds. 1799 is Namecheap's equivalent to 'Automatic'")
 	setOneCmd.Flags().String(setOneKeyFriendlyName, "", "Friendly name")
 	setOneCmd.Flags().Bool(setOneKeyIsActive, true, "Active state")
+	setOneCmd.Flags().String(setOneKeyFlag, "", "CAA flag, e.g.: '0'. Can also be given in the value: '0 issue letsencrypt.org'")
+	setOneCmd.Flags().String(setOneKeyTag, "", "CAA tag, e.g.: 'issue'. Can also be given in the value: '0 issue letsencrypt.org'")
+	setOneCmd.Flags().String(setOneKeyEmailType, "", fmt.Sprintf("Change the domain's email mode to one of %v. If omitted, the current mode is kept", namecheap.EmailTypes))
 
 	setOneCmd.Flags().Bool(setOneKeyDelete, false, "Delete DNS entry")
+	setOneCmd.Flags().Bool(setOneKeyAdd, false, "Append the entry even if others with the same name and type exist")
+	setOneCmd.Flags().Bool(setOneKeyReplaceAll, false, "Replace all entries with the same name and type by this one. With --delete, delete all of them")
+	setOneCmd.Flags().String(setOneKeyMatchAddress, "", "Only update (or delete) the entry with the same name, type and this value")
 
 	setOneCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+	addBackupFlags(setOneCmd)
+	addNameserversFlags(setOneCmd)
 
 	config.ViperBindPFlagSet(setOneCmd, nil)
 }
@@ -67,55 +82,188 @@ func RunSetOne(cmd *cobra.Command, args []string) {
 	inputHost := &namecheap.Host{
 		HostId:       "1",
 		Name:         config.ViperGetString(cmd, setOneKeyName),
-		Type:         config.ViperGetString(cmd, setOneKeyType),
+		Type:         namecheap.RecordType(strings.ToUpper(config.ViperGetString(cmd, setOneKeyType))),
 		Address:      config.ViperGetString(cmd, setOneKeyAddress),
-		MXPref:       config.ViperGetString(cmd, setOneKeyMXPref),
-		TTL:          config.ViperGetString(cmd, setOneKeyTTL),
+		MXPref:       intFlag(cmd, setOneKeyMXPref),
+		TTL:          intFlag(cmd, setOneKeyTTL),
 		FriendlyName: config.ViperGetString(cmd, setOneKeyFriendlyName),
-		IsActive:     config.ViperGetString(cmd, setOneKeyIsActive),
+		IsActive:     namecheap.NewBool(config.ViperGetBool(cmd, setOneKeyIsActive)),
+		Flag:         config.ViperGetString(cmd, setOneKeyFlag),
+		Tag:          config.ViperGetString(cmd, setOneKeyTag),
 	}
 
 	timeout := config.ViperGetDuration(cmd, keyGetTimeout)
 	delete := config.ViperGetBool(cmd, setOneKeyDelete)
+	add := config.ViperGetBool(cmd, setOneKeyAdd)
+	replaceAll := config.ViperGetBool(cmd, setOneKeyReplaceAll)
+	matchAddress := config.ViperGetString(cmd, setOneKeyMatchAddress)
+
+	modes := 0
+	for _, enabled := range []bool{add, replaceAll, len(matchAddress) > 0} {
+		if enabled {
+			modes++
+		}
+	}
+	if modes > 1 {
+		log.Fatalf("Only one of --%s, --%s and --%s can be used at a time", setOneKeyAdd, setOneKeyReplaceAll, setOneKeyMatchAddress)
+	}
+	if add && delete {
+		log.Fatalf("--%s cannot be combined with --%s", setOneKeyAdd, setOneKeyDelete)
+	}
 
 	// download current DNS configuration
 	apiresponse := download(cmd, timeout)
+	hosts := apiresponse.CommandResponse.DomainDNSGetHostsResult.Host
+
+	switch {
+	case add:
+		hosts = appendHost(hosts, *inputHost)
+	case replaceAll:
+		hosts = replaceHosts(hosts, *inputHost, delete)
+	case len(matchAddress) > 0:
+		var found bool
+		hosts, found = mergeHostByAddress(hosts, *inputHost, matchAddress, delete)
+		if !found {
+			log.Fatalf("No '%s' %s entry with value '%s' was found", inputHost.Name, inputHost.Type, matchAddress)
+		}
+	default:
+		hosts = mergeHost(hosts, *inputHost, delete)
+	}
+	validateHosts(hosts)
+	apiresponse.CommandResponse.DomainDNSGetHostsResult.Host = hosts
+	if emailType := config.ViperGetString(cmd, setOneKeyEmailType); len(emailType) > 0 {
+		apiresponse.CommandResponse.DomainDNSGetHostsResult.EmailType = emailType
+	}
+
+	// upload new DNS configuration
+	upload(cmd, apiresponse, timeout)
+}
 
-	found := false
-	for i, host := range apiresponse.CommandResponse.DomainDNSGetHostsResult.Host {
+// mergeHost updates the first host matching name and type of inputHost, or appends it when there is no match.
+// When delete is true, the matched host is cleared instead so it will not be uploaded
+func mergeHost(hosts []namecheap.Host, inputHost namecheap.Host, delete bool) []namecheap.Host {
+	for i, host := range hosts {
 		if host.Name == inputHost.Name && host.Type == inputHost.Type {
 			log.Debugf("Matched host: %#v", host)
 
 			if delete {
-				apiresponse.CommandResponse.DomainDNSGetHostsResult.Host[i] = namecheap.Host{}
-				break
+				hosts[i] = namecheap.Host{}
+				return hosts
 			}
+			hosts[i] = updateHost(host, inputHost)
 
-			host.Address = inputHost.Address
-			if len(inputHost.MXPref) > 0 {
-				host.MXPref = inputHost.MXPref
-			}
-			if len(inputHost.TTL) > 0 {
-				host.TTL = inputHost.TTL
-			}
-			if len(inputHost.FriendlyName) > 0 {
-				host.FriendlyName = inputHost.FriendlyName
+			return hosts
+		}
+	}
+
+	if !delete {
+		inputHost.HostId = nextHostId(hosts)
+		hosts = append(hosts, inputHost)
+	}
+	return hosts
+}
+
+// mergeHostByAddress updates the host matching name and type of inputHost and having matchAddress as value.
+// When delete is true, the matched host is cleared instead. Reports whether a host matched
+func mergeHostByAddress(hosts []namecheap.Host, inputHost namecheap.Host, matchAddress string, delete bool) ([]namecheap.Host, bool) {
+	for i, host := range hosts {
+		if host.Name == inputHost.Name && host.Type == inputHost.Type && host.Address == matchAddress {
+			log.Debugf("Matched host: %#v", host)
+
+			if delete {
+				hosts[i] = namecheap.Host{}
+			} else {
+				hosts[i] = updateHost(host, inputHost)
 			}
-			host.IsActive = inputHost.IsActive
-			apiresponse.CommandResponse.DomainDNSGetHostsResult.Host[i] = host
+			return hosts, true
+		}
+	}
+	return hosts, false
+}
 
-			found = true
-			break
+// replaceHosts clears every host matching name and type of inputHost, then appends inputHost unless delete is true
+func replaceHosts(hosts []namecheap.Host, inputHost namecheap.Host, delete bool) []namecheap.Host {
+	for i, host := range hosts {
+		if host.Name == inputHost.Name && host.Type == inputHost.Type {
+			log.Debugf("Matched host: %#v", host)
+			hosts[i] = namecheap.Host{}
 		}
 	}
 
-	if !found && !delete {
-		apiresponse.CommandResponse.DomainDNSGetHostsResult.Host = append(
-			apiresponse.CommandResponse.DomainDNSGetHostsResult.Host,
-			*inputHost,
-		)
+	if !delete {
+		inputHost.HostId = nextHostId(hosts)
+		hosts = append(hosts, inputHost)
 	}
+	return hosts
+}
 
-	// upload new DNS configuration
-	upload(cmd, apiresponse, timeout)
+// updateHost copies the value and the non empty attributes of inputHost over host
+func updateHost(host namecheap.Host, inputHost namecheap.Host) namecheap.Host {
+	host.Address = inputHost.Address
+	if inputHost.MXPref.Valid {
+		host.MXPref = inputHost.MXPref
+	}
+	if inputHost.TTL.Valid {
+		host.TTL = inputHost.TTL
+	}
+	if len(inputHost.FriendlyName) > 0 {
+		host.FriendlyName = inputHost.FriendlyName
+	}
+	host.IsActive = inputHost.IsActive
+	if len(inputHost.Tag) > 0 {
+		host.Flag = inputHost.Flag
+		host.Tag = inputHost.Tag
+	}
+	return host
+}
+
+// appendHost appends inputHost unless a host with the same name, type and address already exists
+func appendHost(hosts []namecheap.Host, inputHost namecheap.Host) []namecheap.Host {
+	for _, host := range hosts {
+		if sameRecord(host, inputHost) {
+			log.Debugf("Host already exists: %#v", host)
+			return hosts
+		}
+	}
+	inputHost.HostId = nextHostId(hosts)
+	return append(hosts, inputHost)
+}
+
+// removeHost clears every host with the same name, type and address as inputHost, leaving its siblings untouched.
+// Returns how many hosts were removed
+func removeHost(hosts []namecheap.Host, inputHost namecheap.Host) ([]namecheap.Host, int) {
+	removed := 0
+	for i, host := range hosts {
+		if sameRecord(host, inputHost) {
+			log.Debugf("Removing host: %#v", host)
+			hosts[i] = namecheap.Host{}
+			removed++
+		}
+	}
+	return hosts, removed
+}
+
+// sameRecord reports whether both hosts have the same name, type and address
+func sameRecord(a, b namecheap.Host) bool {
+	return a.Name == b.Name && strings.EqualFold(string(a.Type), string(b.Type)) && a.Address == b.Address
+}
+
+// intFlag parses a number flag, which is unset when empty
+func intFlag(cmd *cobra.Command, key string) namecheap.Int {
+	i, err := namecheap.ParseInt(config.ViperGetString(cmd, key))
+	if err != nil {
+		log.Fatalf("Invalid --%s: %v", key, err)
+	}
+	return i
+}
+
+// nextHostId returns a HostId not used by any of the hosts, so appended hosts do not overwrite each other on upload
+func nextHostId(hosts []namecheap.Host) string {
+	max := 0
+	for _, host := range hosts {
+		if id, err := strconv.Atoi(host.HostId); err == nil && id > max {
+			max = id
+		}
+	}
+	return strconv.Itoa(max + 1)
 }
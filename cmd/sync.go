@@ -0,0 +1,335 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/file"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/backup"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keySyncManifest    = "manifest"
+	keySyncConcurrency = "concurrency"
+
+	syncStatusUnchanged = "unchanged"
+	syncStatusPlanned   = "planned"
+	syncStatusApplied   = "applied"
+	syncStatusSkipped   = "skipped"
+	syncStatusFailed    = "failed"
+)
+
+var (
+	requiredSyncFlags = []string{keyCommonUsername, keySyncManifest}
+
+	// syncFormatsByExtension maps file extensions found in a manifest directory to input formats
+	syncFormatsByExtension = map[string]string{
+		".xml":  supportedFormats[0],
+		".yaml": supportedFormats[1],
+		".yml":  supportedFormats[1],
+		".json": supportedFormats[2],
+		".zone": supportedFormats[3],
+		".bind": supportedFormats[3],
+	}
+
+	syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Apply the desired DNS configuration of many domains",
+		Long: `Apply the desired DNS configuration of many domains
+
+--manifest is either a directory with one file per domain, in any supported format detected by extension
+(.xml, .yaml, .yml, .json, .zone, .bind), or a single YAML/JSON file like:
+
+  domains:
+    - domain: example.com
+      emailtype: MX
+      hosts:
+        - name: "@"
+          type: A
+          address: 1.2.3.4
+          ttl: "1799"
+
+Every domain is downloaded and diffed first. Changes are uploaded after confirmation, or straight away with --auto-approve.`,
+		Run: RunSync,
+	}
+)
+
+// syncManifest is the single file form of --manifest
+type syncManifest struct {
+	Domains []syncDomain `json:"domains" yaml:"domains"`
+}
+
+// syncDomain is the desired DNS configuration of one domain
+type syncDomain struct {
+	Domain    string           `json:"domain" yaml:"domain"`
+	EmailType string           `json:"emailtype" yaml:"emailtype"`
+	Hosts     []namecheap.Host `json:"hosts" yaml:"hosts"`
+}
+
+// syncResult is the outcome for one domain
+type syncResult struct {
+	domain  string
+	desired syncDomain
+	current *namecheap.ApiResponse
+	diff    *namecheap.HostsDiff
+	status  string
+	err     error
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	addCommonFlags(syncCmd)
+	addBackupFlags(syncCmd)
+	addNameserversFlags(syncCmd)
+	syncCmd.Flags().StringP(keySyncManifest, "m", "", "[Required] Directory with one file per domain, or a single manifest file")
+	syncCmd.Flags().Int(keySyncConcurrency, 4, "How many domains are processed at the same time")
+	syncCmd.Flags().Bool(keyPlanDryRun, false, "Only show the changes that would be uploaded")
+	syncCmd.Flags().Bool(keyPlanAutoApprove, false, "Upload without asking for confirmation")
+	syncCmd.Flags().Duration(keyGetTimeout, 10, "Request timeout")
+
+	config.ViperBindPFlagSet(syncCmd, nil)
+}
+
+// RunSync plans all domains, then applies the changes with bounded concurrency and prints a per domain report
+func RunSync(cmd *cobra.Command, args []string) {
+	config.CheckRequiredFlags(cmd, requiredSyncFlags)
+
+	concurrency, err := strconv.Atoi(config.ViperGetString(cmd, keySyncConcurrency))
+	if err != nil || concurrency < 1 {
+		log.Fatalf("--%s must be a positive number", keySyncConcurrency)
+	}
+
+	desired := readSyncManifest(config.ViperGetString(cmd, keySyncManifest))
+	log.Infof("Loaded desired configuration of %d domains", len(desired))
+
+	params := setCommonParameters(cmd)
+	client := newClient(params, time.Second*config.ViperGetDuration(cmd, keyGetTimeout))
+	ctx := context.Background()
+
+	results := make([]*syncResult, len(desired))
+	for i, d := range desired {
+		results[i] = &syncResult{domain: strings.ToLower(d.Domain), desired: d}
+	}
+
+	// plan
+	forEachConcurrently(results, concurrency, func(r *syncResult) {
+		planSyncDomain(ctx, client, r)
+	})
+
+	refuse := refuseCustomNameservers(cmd) && !config.ViperGetBool(cmd, keyPlanDryRun)
+	for _, r := range results {
+		if r.status == syncStatusPlanned && !r.current.UsesOurDNS() {
+			if refuse {
+				r.status, r.err = syncStatusFailed, fmt.Errorf("uses custom nameservers, so uploaded host records would have no effect. Use --%s to upload them anyway", keyAllowCustomNameservers)
+				continue
+			}
+			log.Warnf("'%s' uses custom nameservers, so its host records have no effect", r.domain)
+		}
+	}
+
+	pending := 0
+	for _, r := range results {
+		if r.status == syncStatusPlanned {
+			fmt.Printf("# %s\n%s\n\n", r.domain, r.diff)
+			pending++
+		}
+	}
+
+	apply := pending > 0 && !config.ViperGetBool(cmd, keyPlanDryRun)
+	if apply && !config.ViperGetBool(cmd, keyPlanAutoApprove) {
+		apply = confirm(fmt.Sprintf("Do you want to upload the changes of %d domains?", pending))
+	}
+	if apply {
+		backupDir := config.ViperGetString(cmd, keyBackupDir)
+		forEachConcurrently(results, concurrency, func(r *syncResult) {
+			if r.status == syncStatusPlanned {
+				applySyncDomain(ctx, client, backupDir, r)
+			}
+		})
+	} else {
+		for _, r := range results {
+			if r.status == syncStatusPlanned && !config.ViperGetBool(cmd, keyPlanDryRun) {
+				r.status = syncStatusSkipped
+			}
+		}
+	}
+
+	if failed := printSyncReport(results); failed > 0 {
+		log.Fatalf("%d of %d domains failed", failed, len(results))
+	}
+}
+
+// planSyncDomain downloads the current configuration of a domain and diffs it against the desired one
+func planSyncDomain(ctx context.Context, client *namecheap.Client, r *syncResult) {
+	sld, tld, err := splitDomain(r.domain)
+	if err != nil {
+		r.status, r.err = syncStatusFailed, err
+		return
+	}
+	r.current, err = client.GetHosts(ctx, sld, tld)
+	if err != nil {
+		r.status, r.err = syncStatusFailed, err
+		return
+	}
+	r.diff = namecheap.DiffHosts(r.current.CommandResponse.DomainDNSGetHostsResult.Host, r.desired.Hosts)
+	r.diff.EmailType = namecheap.DiffEmailType(r.current.CommandResponse.DomainDNSGetHostsResult.EmailType, r.desired.EmailType)
+	r.status = syncStatusUnchanged
+	if !r.diff.IsEmpty() {
+		r.status = syncStatusPlanned
+	}
+}
+
+// applySyncDomain saves a backup of the current configuration, then uploads the desired one
+func applySyncDomain(ctx context.Context, client *namecheap.Client, backupDir string, r *syncResult) {
+	if len(backupDir) > 0 {
+		path, err := backup.Save(backupDir, r.current, time.Now())
+		if err != nil {
+			r.status, r.err = syncStatusFailed, fmt.Errorf("failed to save backup, not uploading: %w", err)
+			return
+		}
+		log.Debugf("Saved backup of '%s' to '%s'", r.domain, path)
+	}
+
+	sld, tld, _ := splitDomain(r.domain)
+	emailType := r.desired.EmailType
+	if len(emailType) == 0 {
+		emailType = r.current.CommandResponse.DomainDNSGetHostsResult.EmailType
+	}
+	if _, err := client.SetHosts(ctx, sld, tld, r.desired.Hosts, emailType); err != nil {
+		r.status, r.err = syncStatusFailed, err
+		return
+	}
+	r.status = syncStatusApplied
+	log.Infof("Applied '%s': %s", r.domain, r.diff.Summary())
+}
+
+// forEachConcurrently runs fn for every result, at most concurrency at a time
+func forEachConcurrently(results []*syncResult, concurrency int, fn func(*syncResult)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, r := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *syncResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(r)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// printSyncReport prints one line per domain and returns how many failed
+func printSyncReport(results []*syncResult) int {
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tSTATUS\tADDED\tCHANGED\tREMOVED\tERROR")
+	for _, r := range results {
+		added, changed, removed := "-", "-", "-"
+		if r.diff != nil {
+			added, changed, removed = strconv.Itoa(len(r.diff.Added)), strconv.Itoa(len(r.diff.Changed)), strconv.Itoa(len(r.diff.Removed))
+		}
+		message := ""
+		if r.err != nil {
+			message = r.err.Error()
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.domain, r.status, added, changed, removed, message)
+	}
+	_ = w.Flush()
+	return failed
+}
+
+// readSyncManifest reads the desired configuration of all domains from a directory or a single manifest file
+func readSyncManifest(path string) []syncDomain {
+	var domains []syncDomain
+
+	if file.IsFile(path) {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			log.Fatal(err)
+		}
+		manifest := &syncManifest{}
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			err = json.Unmarshal(data, manifest)
+		} else {
+			err = yaml.Unmarshal(data, manifest)
+		}
+		if err != nil {
+			log.Fatalf("Failed to read manifest '%s': %v", path, err)
+		}
+		domains = manifest.Domains
+	} else {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			log.Fatalf("'%s' is neither a manifest file nor a directory: %v", path, err)
+		}
+		for _, e := range entries {
+			format, ok := syncFormatsByExtension[strings.ToLower(filepath.Ext(e.Name()))]
+			if e.IsDir() || !ok {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(path, e.Name()))
+			if err != nil {
+				log.Fatal(err)
+			}
+			result := unmarshal(format, &data).CommandResponse.DomainDNSGetHostsResult
+			if len(result.Domain) == 0 {
+				log.Fatalf("'%s' does not specify its domain", e.Name())
+			}
+			domains = append(domains, syncDomain{Domain: result.Domain, EmailType: result.EmailType, Hosts: result.Host})
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range domains {
+		d := &domains[i]
+		name := strings.ToLower(d.Domain)
+		if len(name) == 0 {
+			log.Fatalf("Manifest entry %d has no domain", i+1)
+		}
+		if seen[name] {
+			log.Fatalf("Domain '%s' is specified more than once", name)
+		}
+		seen[name] = true
+
+		// hosts without id are not uploaded
+		hosts := make([]namecheap.Host, 0, len(d.Hosts))
+		for _, h := range d.Hosts {
+			if !h.IsEmpty() {
+				h.HostId = strconv.Itoa(len(hosts) + 1)
+				hosts = append(hosts, h)
+			}
+		}
+		d.Hosts = hosts
+		if errs := namecheap.ValidateHosts(hosts); len(errs) > 0 {
+			for _, err := range errs {
+				log.Errorf("%s: %v", name, err)
+			}
+			log.Fatalf("Found %d problems in the DNS configuration of '%s'", len(errs), name)
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].Domain < domains[j].Domain
+	})
+	return domains
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2023 Dataflows
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/thedataflows/go-commons/pkg/config"
+	"github.com/thedataflows/go-commons/pkg/log"
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check DNS configuration for mistakes without calling the API",
+		Long: `Check DNS configuration for mistakes without calling the API
+
+Checks A/AAAA addresses, record names, TTL bounds (60-60000), MX preferences, SRV and CAA values,
+CNAME at the apex and CNAME records sharing their name with other records.
+The same checks run before 'set', 'setone' and 'sync' upload anything.`,
+		Aliases: []string{"v"},
+		Run:     RunValidate,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringP(keySetInputFile, "i", "", "Input file. If omitted, stdin is used until 2 consecutive newlines are detected")
+	validateCmd.Flags().String(keySetInputFormat, supportedFormats[0], fmt.Sprintf("Input format. Supported: %v", supportedFormats))
+
+	config.ViperBindPFlagSet(validateCmd, nil)
+}
+
+// RunValidate reads the input configuration and reports every problem found
+func RunValidate(cmd *cobra.Command, args []string) {
+	format := config.ViperGetString(cmd, keySetInputFormat)
+	if !slices.Contains(supportedFormats, format) {
+		log.Fatalf("Input format '%s' is not supported. Please use one of: %v", format, supportedFormats)
+	}
+
+	hosts := unmarshal(format, readInput(cmd)).CommandResponse.DomainDNSGetHostsResult.Host
+	validateHosts(hosts)
+	log.Infof("%d hosts are valid", len(hosts))
+}
+
+// validateHosts logs every problem found in hosts and exits when there is any
+func validateHosts(hosts []namecheap.Host) {
+	errs := namecheap.ValidateHosts(hosts)
+	if len(errs) == 0 {
+		return
+	}
+	for _, err := range errs {
+		log.Error(err)
+	}
+	log.Fatalf("Found %d problems in the DNS configuration", len(errs))
+}
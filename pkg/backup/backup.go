@@ -0,0 +1,107 @@
+// Package backup stores timestamped snapshots of a domain's DNS configuration and reads them back
+package backup
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+const (
+	// timeLayout is used in snapshot file names, so they sort chronologically
+	timeLayout = "20060102T150405.000000000Z"
+	extension  = ".xml"
+)
+
+// Snapshot is a stored DNS configuration
+type Snapshot struct {
+	Domain string
+	Time   time.Time
+	Path   string
+}
+
+// DefaultDir returns the default backup directory, under the user's config directory
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", "backups")
+	}
+	return filepath.Join(dir, "namecheap-cli", "backups")
+}
+
+// Save writes response as a snapshot of its domain taken at t and returns the file path
+func Save(dir string, response *namecheap.ApiResponse, t time.Time) (string, error) {
+	domain := strings.ToLower(response.CommandResponse.DomainDNSGetHostsResult.Domain)
+	if len(domain) == 0 || strings.ContainsAny(domain, `/\`) {
+		return "", fmt.Errorf("invalid domain '%s'", domain)
+	}
+
+	data, err := xml.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	domainDir := filepath.Join(dir, domain)
+	if err := os.MkdirAll(domainDir, 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(domainDir, fmt.Sprintf("%s-%s%s", domain, t.UTC().Format(timeLayout), extension))
+	if err := os.WriteFile(path, append([]byte(xml.Header), data...), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// List returns the snapshots of domain, newest first
+func List(dir, domain string) ([]Snapshot, error) {
+	domain = strings.ToLower(domain)
+	entries, err := os.ReadDir(filepath.Join(dir, domain))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := domain + "-"
+	var snapshots []Snapshot
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, extension) {
+			continue
+		}
+		t, err := time.Parse(timeLayout, strings.TrimSuffix(strings.TrimPrefix(name, prefix), extension))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Domain: domain,
+			Time:   t,
+			Path:   filepath.Join(dir, domain, name),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Load reads a snapshot
+func Load(path string) (*namecheap.ApiResponse, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	response := &namecheap.ApiResponse{}
+	if err := xml.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("invalid snapshot '%s': %w", path, err)
+	}
+	return response, nil
+}
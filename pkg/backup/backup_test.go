@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+func response(domain string, hosts ...namecheap.Host) *namecheap.ApiResponse {
+	var v namecheap.ApiResponse
+	v.CommandResponse.DomainDNSGetHostsResult.Domain = domain
+	v.CommandResponse.DomainDNSGetHostsResult.EmailType = "MX"
+	v.CommandResponse.DomainDNSGetHostsResult.Host = hosts
+	return &v
+}
+
+func TestSaveListLoad(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2023, 2, 16, 9, 0, 0, 0, time.UTC)
+	second := first.Add(time.Nanosecond)
+
+	older := response("Example.com", namecheap.Host{Name: "@", Type: "A", Address: "192.0.2.1"})
+	newer := response("example.com", namecheap.Host{Name: "@", Type: "A", Address: "192.0.2.2"})
+	if _, err := Save(dir, older, first); err != nil {
+		t.Fatal(err)
+	}
+	path, err := Save(dir, newer, second.In(time.FixedZone("CET", 3600)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "example.com", "example.com-20230216T090000.000000001Z.xml"); path != want {
+		t.Errorf("Save() path = %s, want %s", path, want)
+	}
+	// files that are not snapshots of the domain are skipped
+	for _, name := range []string{"notes.txt", "example.com-latest.xml", "example.net-20230216T090000.000000000Z.xml"} {
+		if err := os.WriteFile(filepath.Join(dir, "example.com", name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := List(dir, "EXAMPLE.COM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || !snapshots[0].Time.Equal(second) || !snapshots[1].Time.Equal(first) {
+		t.Fatalf("List() = %+v, want the two snapshots newest first", snapshots)
+	}
+
+	loaded, err := Load(snapshots[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := loaded.CommandResponse.DomainDNSGetHostsResult
+	if result.Domain != "example.com" || result.EmailType != "MX" {
+		t.Errorf("Load() = %+v", result)
+	}
+	if diff := namecheap.DiffHosts(result.Host, newer.CommandResponse.DomainDNSGetHostsResult.Host); !diff.IsEmpty() {
+		t.Errorf("loaded hosts differ from the saved ones:\n%s", diff)
+	}
+}
+
+func TestListMissing(t *testing.T) {
+	snapshots, err := List(t.TempDir(), "example.com")
+	if err != nil || len(snapshots) != 0 {
+		t.Errorf("List() = %v, %v, want no snapshots", snapshots, err)
+	}
+}
+
+func TestSaveInvalidDomain(t *testing.T) {
+	for _, domain := range []string{"", "../example.com", `example\com`} {
+		if _, err := Save(t.TempDir(), response(domain), time.Now()); err == nil {
+			t.Errorf("Save() of domain %q succeeded", domain)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "example.com-20230216T090000.000000000Z.xml")
+	if err := os.WriteFile(path, []byte("<ApiResponse"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "invalid snapshot") {
+		t.Errorf("Load() error = %v, want an invalid snapshot error", err)
+	}
+}
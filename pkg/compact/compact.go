@@ -0,0 +1,159 @@
+// Package compact converts between a short, human friendly record list and Namecheap DNS configuration.
+// It drops the API envelope and HostIds, and moves the most common TTL to the defaults, e.g.:
+//
+//	domain: example.com
+//	emailtype: MX
+//	defaults:
+//	  ttl: 1799
+//	records:
+//	  - {name: "@", type: A, address: 1.2.3.4}
+//	  - {name: "@", type: MX, address: mail.example.com., mxpref: 10}
+package compact
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+	"gopkg.in/yaml.v3"
+)
+
+// Zone is the compact form of a domain's DNS configuration
+type Zone struct {
+	Domain    string    `json:"domain" yaml:"domain"`
+	EmailType string    `json:"emailtype,omitempty" yaml:"emailtype,omitempty"`
+	Defaults  *Defaults `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	Records   []Record  `json:"records" yaml:"records"`
+}
+
+// Defaults apply to every record that does not set the attribute itself
+type Defaults struct {
+	TTL namecheap.Int `json:"ttl" yaml:"ttl,omitempty"`
+}
+
+// Record is a host without the attributes only the API cares about
+type Record struct {
+	Name         string               `json:"name" yaml:"name"`
+	Type         namecheap.RecordType `json:"type" yaml:"type"`
+	Address      string               `json:"address" yaml:"address"`
+	MXPref       namecheap.Int        `json:"mxpref" yaml:"mxpref,omitempty"`
+	TTL          namecheap.Int        `json:"ttl" yaml:"ttl,omitempty"`
+	FriendlyName string               `json:"friendlyname,omitempty" yaml:"friendlyname,omitempty"`
+	IsActive     namecheap.Bool       `json:"isactive" yaml:"isactive,omitempty"`
+	Flag         string               `json:"flag,omitempty" yaml:"flag,omitempty"`
+	Tag          string               `json:"tag,omitempty" yaml:"tag,omitempty"`
+}
+
+// Detect reports whether data, YAML or JSON, is a compact zone rather than an API envelope.
+// A compact zone has a top level 'records' key
+func Detect(data []byte) bool {
+	var top map[string]interface{}
+	if err := yaml.Unmarshal(data, &top); err != nil {
+		return false
+	}
+	for key := range top {
+		if strings.EqualFold(key, "records") {
+			return true
+		}
+	}
+	return false
+}
+
+// Unmarshal parses a compact zone, YAML or JSON, into v. HostIds are assigned in order
+func Unmarshal(data []byte, v *namecheap.ApiResponse) error {
+	zone := &Zone{}
+	if err := yaml.Unmarshal(data, zone); err != nil {
+		return err
+	}
+
+	hosts := make([]namecheap.Host, 0, len(zone.Records))
+	for i, r := range zone.Records {
+		if len(r.Type) == 0 || len(r.Address) == 0 {
+			return fmt.Errorf("record %d: type and address are required", i+1)
+		}
+		host := namecheap.Host{
+			HostId:       strconv.Itoa(len(hosts) + 1),
+			Name:         r.Name,
+			Type:         r.Type,
+			Address:      r.Address,
+			MXPref:       r.MXPref,
+			TTL:          r.TTL,
+			FriendlyName: r.FriendlyName,
+			IsActive:     r.IsActive,
+			Flag:         r.Flag,
+			Tag:          r.Tag,
+		}
+		if len(host.Name) == 0 {
+			host.Name = "@"
+		}
+		if !host.TTL.Valid && zone.Defaults != nil {
+			host.TTL = zone.Defaults.TTL
+		}
+		hosts = append(hosts, host)
+	}
+
+	v.Status = "OK"
+	v.CommandResponse.Type = "namecheap.domains.dns.getHosts"
+	v.CommandResponse.DomainDNSGetHostsResult.Domain = zone.Domain
+	v.CommandResponse.DomainDNSGetHostsResult.EmailType = zone.EmailType
+	v.CommandResponse.DomainDNSGetHostsResult.Host = hosts
+	return nil
+}
+
+// Marshal renders v as a compact zone in YAML. The most common TTL becomes the default
+func Marshal(v *namecheap.ApiResponse) ([]byte, error) {
+	result := v.CommandResponse.DomainDNSGetHostsResult
+	zone := &Zone{
+		Domain:    result.Domain,
+		EmailType: result.EmailType,
+		Records:   make([]Record, 0, len(result.Host)),
+	}
+
+	defaultTTL := mostCommonTTL(result.Host)
+	if defaultTTL.Valid {
+		zone.Defaults = &Defaults{TTL: defaultTTL}
+	}
+	for _, h := range result.Host {
+		if h.IsEmpty() {
+			continue
+		}
+		r := Record{
+			Name:         h.Name,
+			Type:         h.Type,
+			Address:      h.Address,
+			FriendlyName: h.FriendlyName,
+			Flag:         h.Flag,
+			Tag:          h.Tag,
+		}
+		if strings.EqualFold(string(h.Type), string(namecheap.RecordTypeMX)) {
+			r.MXPref = h.MXPref
+		}
+		if h.TTL != defaultTTL {
+			r.TTL = h.TTL
+		}
+		if h.IsActive.IsFalse() {
+			r.IsActive = h.IsActive
+		}
+		zone.Records = append(zone.Records, r)
+	}
+
+	return yaml.Marshal(zone)
+}
+
+// mostCommonTTL returns the TTL used by most hosts, the lowest one on ties
+func mostCommonTTL(hosts []namecheap.Host) namecheap.Int {
+	counts := map[int]int{}
+	for _, h := range hosts {
+		if h.TTL.Valid && !h.IsEmpty() {
+			counts[h.TTL.Value]++
+		}
+	}
+	var ttl namecheap.Int
+	for value, count := range counts {
+		if !ttl.Valid || count > counts[ttl.Value] || (count == counts[ttl.Value] && value < ttl.Value) {
+			ttl = namecheap.NewInt(value)
+		}
+	}
+	return ttl
+}
@@ -0,0 +1,154 @@
+package compact
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"domain: example.com\nrecords: []\n", true},
+		{`{"Domain": "example.com", "Records": []}`, true},
+		{"CommandResponse:\n  DomainDNSGetHostsResult:\n    Host: []\n", false},
+		{`{"CommandResponse": {"DomainDNSGetHostsResult": {"Host": []}}}`, false},
+		{"<ApiResponse/>", false},
+		{"[1, 2]", false},
+	}
+	for _, tt := range tests {
+		if got := Detect([]byte(tt.in)); got != tt.want {
+			t.Errorf("Detect(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	in := `domain: example.com
+emailtype: MX
+defaults:
+  ttl: 1799
+records:
+  - {type: A, address: 192.0.2.1}
+  - {name: www, type: CNAME, address: example.com., ttl: 300}
+  - {name: "@", type: MX, address: mail.example.com., mxpref: 10}
+  - {name: old, type: A, address: 192.0.2.2, isactive: false}
+`
+	var v namecheap.ApiResponse
+	if err := Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []namecheap.Host{
+		{HostId: "1", Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.1", TTL: namecheap.NewInt(1799)},
+		{HostId: "2", Name: "www", Type: namecheap.RecordTypeCNAME, Address: "example.com.", TTL: namecheap.NewInt(300)},
+		{HostId: "3", Name: "@", Type: namecheap.RecordTypeMX, Address: "mail.example.com.", MXPref: namecheap.NewInt(10), TTL: namecheap.NewInt(1799)},
+		{HostId: "4", Name: "old", Type: namecheap.RecordTypeA, Address: "192.0.2.2", TTL: namecheap.NewInt(1799), IsActive: namecheap.NewBool(false)},
+	}
+	result := v.CommandResponse.DomainDNSGetHostsResult
+	if result.Domain != "example.com" || result.EmailType != "MX" {
+		t.Errorf("domain = %q, email type = %q", result.Domain, result.EmailType)
+	}
+	if !reflect.DeepEqual(result.Host, want) {
+		t.Errorf("hosts =\n%+v\nwant\n%+v", result.Host, want)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"records:\n  - {name: www, address: example.com.}\n", "record 1: type and address are required"},
+		{"records:\n  - {type: A, address: 192.0.2.1}\n  - {type: A}\n", "record 2: type and address are required"},
+		{"records:\n  - {type: A, address: 192.0.2.1, ttl: soon}\n", "soon"},
+	}
+	for _, tt := range tests {
+		var v namecheap.ApiResponse
+		if err := Unmarshal([]byte(tt.in), &v); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Unmarshal(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	var v namecheap.ApiResponse
+	v.CommandResponse.DomainDNSGetHostsResult.Domain = "example.com"
+	v.CommandResponse.DomainDNSGetHostsResult.EmailType = "MX"
+	v.CommandResponse.DomainDNSGetHostsResult.Host = []namecheap.Host{
+		{HostId: "1", Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.1", MXPref: namecheap.NewInt(10), TTL: namecheap.NewInt(1799), IsActive: namecheap.NewBool(true)},
+		{HostId: "2", Name: "www", Type: namecheap.RecordTypeCNAME, Address: "example.com.", MXPref: namecheap.NewInt(10), TTL: namecheap.NewInt(300), IsActive: namecheap.NewBool(true)},
+		{HostId: "3", Name: "@", Type: namecheap.RecordTypeMX, Address: "mail.example.com.", MXPref: namecheap.NewInt(20), TTL: namecheap.NewInt(1799), IsActive: namecheap.NewBool(true)},
+		{HostId: "4", Name: "old", Type: namecheap.RecordTypeA, Address: "192.0.2.2", MXPref: namecheap.NewInt(10), TTL: namecheap.NewInt(300), IsActive: namecheap.NewBool(false)},
+		{HostId: "5", Name: "@", Type: namecheap.RecordTypeCAA, Address: "letsencrypt.org", TTL: namecheap.NewInt(1799), Flag: "0", Tag: "issue"},
+		{},
+	}
+
+	data, err := Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `domain: example.com
+emailtype: MX
+defaults:
+    ttl: 1799
+records:
+    - name: '@'
+      type: A
+      address: 192.0.2.1
+    - name: www
+      type: CNAME
+      address: example.com.
+      ttl: 300
+    - name: '@'
+      type: MX
+      address: mail.example.com.
+      mxpref: 20
+    - name: old
+      type: A
+      address: 192.0.2.2
+      ttl: 300
+      isactive: false
+    - name: '@'
+      type: CAA
+      address: letsencrypt.org
+      flag: "0"
+      tag: issue
+`
+	if string(data) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, want)
+	}
+
+	var back namecheap.ApiResponse
+	if err := Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if diff := namecheap.DiffHosts(v.CommandResponse.DomainDNSGetHostsResult.Host, back.CommandResponse.DomainDNSGetHostsResult.Host); !diff.IsEmpty() {
+		t.Errorf("round trip changed the hosts:\n%s", diff)
+	}
+}
+
+func TestMostCommonTTL(t *testing.T) {
+	host := func(ttl int) namecheap.Host {
+		return namecheap.Host{Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.1", TTL: namecheap.NewInt(ttl)}
+	}
+	tests := []struct {
+		name  string
+		hosts []namecheap.Host
+		want  namecheap.Int
+	}{
+		{name: "none", want: namecheap.Int{}},
+		{name: "unset ttls", hosts: []namecheap.Host{{Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.1"}}, want: namecheap.Int{}},
+		{name: "most common", hosts: []namecheap.Host{host(300), host(1799), host(1799)}, want: namecheap.NewInt(1799)},
+		{name: "lowest on ties", hosts: []namecheap.Host{host(1799), host(300), host(60), host(1799), host(60)}, want: namecheap.NewInt(60)},
+	}
+	for _, tt := range tests {
+		if got := mostCommonTTL(tt.hosts); got != tt.want {
+			t.Errorf("%s: mostCommonTTL() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
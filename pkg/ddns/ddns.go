@@ -0,0 +1,173 @@
+// Package ddns detects the public IP addresses of this machine and remembers the last ones published
+package ddns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// TypeA is the record type holding IPv4 addresses
+	TypeA = "A"
+	// TypeAAAA is the record type holding IPv6 addresses
+	TypeAAAA = "AAAA"
+)
+
+var (
+	// DefaultIPv4URLs are HTTP endpoints echoing the caller's public IPv4 address
+	DefaultIPv4URLs = []string{"https://api.ipify.org", "https://ipv4.icanhazip.com"}
+	// DefaultIPv6URLs are HTTP endpoints echoing the caller's public IPv6 address
+	DefaultIPv6URLs = []string{"https://api6.ipify.org", "https://ipv6.icanhazip.com"}
+)
+
+// DetectFromURLs asks each echo endpoint in turn for the public address of recordType until one answers
+func DetectFromURLs(ctx context.Context, recordType string, urls []string, timeout time.Duration) (net.IP, error) {
+	network, err := networkOf(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	var errs []error
+	for _, u := range urls {
+		ip, err := fetchIP(ctx, client, u)
+		if err == nil && !matchesType(ip, recordType) {
+			err = fmt.Errorf("'%s' is not a valid %s address", ip, recordType)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+			continue
+		}
+		return ip, nil
+	}
+	return nil, fmt.Errorf("failed to detect public %s address: %w", recordType, errors.Join(errs...))
+}
+
+// DetectFromInterface returns the first global unicast address of recordType assigned to the named interface
+func DetectFromInterface(name, recordType string) (net.IP, error) {
+	if _, err := networkOf(recordType); err != nil {
+		return nil, err
+	}
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if matchesType(ipNet.IP, recordType) {
+			return ipNet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("interface '%s' has no %s address", name, recordType)
+}
+
+// fetchIP performs a GET request and parses the trimmed body as an IP address
+func fetchIP(ctx context.Context, client *http.Client, url string) (net.IP, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("response is not an IP address: %q", strings.TrimSpace(string(body)))
+	}
+	return ip, nil
+}
+
+// networkOf returns the dial network for recordType
+func networkOf(recordType string) (string, error) {
+	switch recordType {
+	case TypeA:
+		return "tcp4", nil
+	case TypeAAAA:
+		return "tcp6", nil
+	}
+	return "", fmt.Errorf("record type '%s' is not an address type", recordType)
+}
+
+// matchesType reports whether ip belongs to the family of recordType
+func matchesType(ip net.IP, recordType string) bool {
+	if recordType == TypeA {
+		return ip.To4() != nil
+	}
+	return ip.To4() == nil && ip.To16() != nil
+}
+
+// State maps a record, see StateKey, to the address last published for it
+type State map[string]string
+
+// StateKey identifies a record in the State
+func StateKey(domain, host, recordType string) string {
+	return fmt.Sprintf("%s|%s|%s", strings.ToLower(domain), strings.ToLower(host), recordType)
+}
+
+// LoadState reads the state file. A missing file yields an empty State
+func LoadState(path string) (State, error) {
+	state := State{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file '%s': %w", path, err)
+	}
+	return state, nil
+}
+
+// Save atomically writes the state file
+func (s State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
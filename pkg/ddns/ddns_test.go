@@ -0,0 +1,97 @@
+package ddns
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectFromURLs(t *testing.T) {
+	echo := func(status int, body string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+	failing := echo(http.StatusServiceUnavailable, "")
+	garbage := echo(http.StatusOK, "<html>")
+	ipv4 := echo(http.StatusOK, "198.51.100.7\n")
+	ipv6 := echo(http.StatusOK, "2001:db8::7")
+
+	tests := []struct {
+		name       string
+		recordType string
+		urls       []string
+		want       string
+		wantErr    string
+	}{
+		{name: "first answering endpoint wins", recordType: TypeA, urls: []string{failing, garbage, ipv4}, want: "198.51.100.7"},
+		{name: "address of the wrong family", recordType: TypeA, urls: []string{ipv6}, wantErr: "is not a valid A address"},
+		{name: "all failing", recordType: TypeA, urls: []string{failing, garbage}, wantErr: "response is not an IP address"},
+		{name: "not an address type", recordType: "CNAME", urls: []string{ipv4}, wantErr: "is not an address type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, err := DetectFromURLs(context.Background(), tt.recordType, tt.urls, time.Second)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DetectFromURLs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || ip.String() != tt.want {
+				t.Fatalf("DetectFromURLs() = %s, %v, want %s", ip, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFromInterface(t *testing.T) {
+	if _, err := DetectFromInterface("no-such-interface0", TypeA); err == nil {
+		t.Error("DetectFromInterface() of a missing interface succeeded")
+	}
+	if _, err := DetectFromInterface("lo", "MX"); err == nil || !strings.Contains(err.Error(), "is not an address type") {
+		t.Errorf("DetectFromInterface() of MX = %v, want an address type error", err)
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "ddns.json")
+
+	state, err := LoadState(path)
+	if err != nil || len(state) != 0 {
+		t.Fatalf("LoadState() of a missing file = %v, %v, want an empty state", state, err)
+	}
+
+	state[StateKey("Example.com", "WWW", TypeA)] = "198.51.100.7"
+	state[StateKey("example.com", "@", TypeAAAA)] = "2001:db8::7"
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := State{"example.com|www|A": "198.51.100.7", "example.com|@|AAAA": "2001:db8::7"}
+	if !reflect.DeepEqual(loaded, want) {
+		t.Errorf("LoadState() = %v, want %v", loaded, want)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file was left behind: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadState(path); err == nil || !strings.Contains(err.Error(), "invalid state file") {
+		t.Errorf("LoadState() of a corrupt file = %v, want an error", err)
+	}
+}
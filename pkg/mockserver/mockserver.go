@@ -0,0 +1,619 @@
+// Package mockserver is an in-process fake of the Namecheap XML API, keeping DNS configuration in memory.
+// It is meant for tests and offline development, e.g.: httptest.NewServer(mockserver.New())
+package mockserver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+const xmlns = "http://api.namecheap.com/xml.response"
+
+// defaultNameservers are the nameservers of domains using Namecheap BasicDNS
+var defaultNameservers = []string{"dns1.registrar-servers.com", "dns2.registrar-servers.com"}
+
+// Server implements http.Handler answering like the Namecheap API
+type Server struct {
+	mu        sync.Mutex
+	apiUser   string
+	apiKey    string
+	rateLimit int
+	requests  []time.Time
+	domains   map[string]*domain
+	now       func() time.Time
+}
+
+// domain is the in-memory DNS configuration of one domain
+type domain struct {
+	id          int
+	emailType   string
+	usingOurDNS bool
+	nameservers []string
+	locked      bool
+	created     time.Time
+	expires     time.Time
+	nextHostId  int
+	hosts       []namecheap.Host
+	forwards    []namecheap.EmailForward
+	glue        map[string]string
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithCredentials makes the server reject requests with a different API user or key
+func WithCredentials(apiUser, apiKey string) Option {
+	return func(s *Server) {
+		s.apiUser = apiUser
+		s.apiKey = apiKey
+	}
+}
+
+// WithRateLimit makes the server answer 'too many requests' above perMinute requests in any minute. Zero disables it
+func WithRateLimit(perMinute int) Option {
+	return func(s *Server) {
+		s.rateLimit = perMinute
+	}
+}
+
+// WithDomain seeds the server with a domain and its hosts
+func WithDomain(name, emailType string, hosts ...namecheap.Host) Option {
+	return func(s *Server) {
+		s.AddDomain(name, emailType, hosts...)
+	}
+}
+
+// New returns a Server configured with the given options
+func New(opts ...Option) *Server {
+	s := &Server{
+		domains: map[string]*domain{},
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddDomain adds or replaces a domain, registered a year ago and expiring in a year. Hosts get new HostIds
+func (s *Server) AddDomain(name, emailType string, hosts ...namecheap.Host) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	d := &domain{
+		id:          len(s.domains) + 1,
+		emailType:   emailType,
+		usingOurDNS: true,
+		nameservers: defaultNameservers,
+		glue:        map[string]string{},
+		created:     now.AddDate(-1, 0, 0),
+		expires:     now.AddDate(1, 0, 0),
+		nextHostId:  1,
+	}
+	d.setHosts(hosts)
+	s.domains[strings.ToLower(name)] = d
+}
+
+// SetExpiry changes when a domain expires. It reports whether the domain exists
+func (s *Server) SetExpiry(name string, expires time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.domains[strings.ToLower(name)]
+	if ok {
+		d.expires = expires
+	}
+	return ok
+}
+
+// Hosts returns a copy of the hosts of a domain, or nil when the domain does not exist
+func (s *Server) Hosts(name string) []namecheap.Host {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.domains[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	return append([]namecheap.Host(nil), d.hosts...)
+}
+
+// ServeHTTP dispatches the API command
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	started := s.now()
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	command := r.Form.Get("Command")
+
+	s.mu.Lock()
+	result, errs := s.handle(command, r)
+	s.mu.Unlock()
+
+	resp := &response{
+		Status:            "OK",
+		Xmlns:             xmlns,
+		RequestedCommand:  strings.ToLower(command),
+		Server:            "MOCK",
+		GMTTimeDifference: "--0:00",
+	}
+	if len(errs) > 0 {
+		resp.Status = "ERROR"
+		resp.Errors.Error = errs
+	} else {
+		resp.CommandResponse = &commandResponse{Type: command, Result: result}
+	}
+	resp.ExecutionTime = fmt.Sprintf("%.3f", s.now().Sub(started).Seconds())
+
+	body, err := xml.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
+
+// handle runs a command with the lock held, returning either a result or errors
+func (s *Server) handle(command string, r *http.Request) (interface{}, []namecheap.Message) {
+	if s.limited() {
+		return nil, errorMessage(namecheap.ErrNumberTooManyRequests, "Too many requests")
+	}
+	if msgs := s.authenticate(r); msgs != nil {
+		return nil, msgs
+	}
+
+	switch strings.ToLower(command) {
+	case "namecheap.domains.dns.gethosts":
+		d, name, msgs := s.domainOf(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		return &getHostsResult{
+			Domain:        name,
+			EmailType:     d.emailType,
+			IsUsingOurDNS: strconv.FormatBool(d.usingOurDNS),
+			Hosts:         d.hosts,
+		}, nil
+	case "namecheap.domains.dns.sethosts":
+		d, name, msgs := s.domainOf(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		hosts, msgs := hostsFromForm(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		if emailType := r.Form.Get("EmailType"); len(emailType) > 0 {
+			d.emailType = strings.ToUpper(emailType)
+		}
+		d.setHosts(hosts)
+		return &setHostsResult{Domain: name, IsSuccess: "true"}, nil
+	case "namecheap.domains.dns.getlist":
+		d, name, msgs := s.domainOf(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		return &nameserversResult{Nameservers: namecheap.Nameservers{
+			Domain:        name,
+			IsUsingOurDNS: namecheap.NewBool(d.usingOurDNS),
+			Nameserver:    d.nameservers,
+		}}, nil
+	case "namecheap.domains.dns.setdefault":
+		d, name, msgs := s.domainOf(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		d.usingOurDNS, d.nameservers = true, defaultNameservers
+		return &setNameserversResult{XMLName: xml.Name{Local: "DomainDNSSetDefaultResult"}, Domain: name, Updated: "true"}, nil
+	case "namecheap.domains.dns.setcustom":
+		d, name, msgs := s.domainOf(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		var nameservers []string
+		for _, ns := range strings.Split(r.Form.Get("Nameservers"), ",") {
+			if ns = strings.TrimSpace(ns); len(ns) > 0 {
+				nameservers = append(nameservers, strings.ToLower(ns))
+			}
+		}
+		if len(nameservers) == 0 {
+			return nil, errorMessage(namecheap.ErrNumberParameterMissing, "Parameter Nameservers is missing")
+		}
+		d.usingOurDNS, d.nameservers = false, nameservers
+		return &setNameserversResult{XMLName: xml.Name{Local: "DomainDNSSetCustomResult"}, Domain: name, Updated: "true"}, nil
+	case "namecheap.domains.dns.getemailforwarding":
+		name := strings.ToLower(r.Form.Get("DomainName"))
+		d, ok := s.domains[name]
+		if !ok {
+			return nil, errorMessage(namecheap.ErrNumberDomainNotFound, "Domain name not found")
+		}
+		return &emailForwardingResult{Domain: name, Forwards: d.forwards}, nil
+	case "namecheap.domains.dns.setemailforwarding":
+		name := strings.ToLower(r.Form.Get("DomainName"))
+		d, ok := s.domains[name]
+		if !ok {
+			return nil, errorMessage(namecheap.ErrNumberDomainNotFound, "Domain name not found")
+		}
+		var forwards []namecheap.EmailForward
+		for i := 1; len(r.Form.Get(fmt.Sprintf("MailBox%d", i))) > 0; i++ {
+			forwards = append(forwards, namecheap.EmailForward{
+				Mailbox:   r.Form.Get(fmt.Sprintf("MailBox%d", i)),
+				ForwardTo: r.Form.Get(fmt.Sprintf("ForwardTo%d", i)),
+			})
+		}
+		d.forwards = forwards
+		return &setEmailForwardingResult{Domain: name, IsSuccess: "true"}, nil
+	case "namecheap.domains.ns.create", "namecheap.domains.ns.delete", "namecheap.domains.ns.getinfo", "namecheap.domains.ns.update":
+		d, name, msgs := s.domainOf(r)
+		if msgs != nil {
+			return nil, msgs
+		}
+		return childNameserver(strings.ToLower(command), d, name, r)
+	case "namecheap.domains.getlist":
+		return s.domainList(r)
+	case "namecheap.domains.getinfo":
+		name := strings.ToLower(r.Form.Get("DomainName"))
+		d, ok := s.domains[name]
+		if !ok {
+			return nil, errorMessage(namecheap.ErrNumberDomainNotFound, "Domain name not found")
+		}
+		info := &domainInfoResult{DomainInfo: namecheap.DomainInfo{
+			Status:     "Ok",
+			ID:         strconv.Itoa(d.id),
+			DomainName: name,
+			OwnerName:  s.apiUser,
+			IsOwner:    namecheap.NewBool(true),
+			IsPremium:  namecheap.NewBool(false),
+		}}
+		info.DomainDetails.CreatedDate = d.created.Format(namecheap.DateLayout)
+		info.DomainDetails.ExpiredDate = d.expires.Format(namecheap.DateLayout)
+		info.Whoisguard.Enabled = "True"
+		info.DnsDetails.ProviderType = "FREE"
+		info.DnsDetails.IsUsingOurDNS = namecheap.NewBool(d.usingOurDNS)
+		info.DnsDetails.HostCount = len(d.hosts)
+		info.DnsDetails.EmailType = d.emailType
+		info.DnsDetails.Nameserver = d.nameservers
+		return info, nil
+	case "namecheap.domains.getregistrarlock":
+		name := strings.ToLower(r.Form.Get("DomainName"))
+		d, ok := s.domains[name]
+		if !ok {
+			return nil, errorMessage(namecheap.ErrNumberDomainNotFound, "Domain name not found")
+		}
+		return &registrarLockResult{Domain: name, RegistrarLockStatus: strconv.FormatBool(d.locked)}, nil
+	}
+
+	return nil, errorMessage(namecheap.ErrNumberUnknownCommand, fmt.Sprintf("Command '%s' is not supported", command))
+}
+
+// childNameserver answers the domains.ns commands, keeping the glue IP of each nameserver
+func childNameserver(command string, d *domain, name string, r *http.Request) (interface{}, []namecheap.Message) {
+	nameserver := strings.ToLower(r.Form.Get("Nameserver"))
+	if !strings.HasSuffix(nameserver, "."+name) {
+		return nil, errorMessage(namecheap.ErrNumberParameterMissing, "Parameter Nameserver is missing or not under the domain")
+	}
+	ip, exists := d.glue[nameserver]
+	result := &namecheap.ChildNameserverResult{Domain: name, Nameserver: nameserver, IsSuccess: namecheap.NewBool(true)}
+
+	switch command {
+	case "namecheap.domains.ns.create":
+		if exists {
+			return nil, errorMessage(namecheap.ErrNumberUnableToProcess, "Nameserver already exists")
+		}
+		d.glue[nameserver] = r.Form.Get("IP")
+		result.XMLName.Local, result.IP = "DomainNSCreateResult", d.glue[nameserver]
+		return result, nil
+	}
+
+	if !exists {
+		return nil, errorMessage(namecheap.ErrNumberUnableToProcess, "Nameserver does not exist")
+	}
+	switch command {
+	case "namecheap.domains.ns.delete":
+		delete(d.glue, nameserver)
+		result.XMLName.Local = "DomainNSDeleteResult"
+	case "namecheap.domains.ns.update":
+		if r.Form.Get("OldIP") != ip {
+			return nil, errorMessage(namecheap.ErrNumberUnableToProcess, "OldIP does not match the current IP")
+		}
+		d.glue[nameserver] = r.Form.Get("IP")
+		result.XMLName.Local = "DomainNSUpdateResult"
+	default:
+		result = &namecheap.ChildNameserverResult{Domain: name, Nameserver: nameserver, IP: ip, Statuses: &namecheap.NameserverStatuses{Status: []string{"ok", "linked"}}}
+		result.XMLName.Local = "DomainNSInfoResult"
+	}
+	return result, nil
+}
+
+// domainList answers domains.getList, supporting the list type, search term, name and expiry sorting and paging
+func (s *Server) domainList(r *http.Request) (interface{}, []namecheap.Message) {
+	now := s.now()
+	listType := strings.ToUpper(r.Form.Get("ListType"))
+	search := strings.ToLower(r.Form.Get("SearchTerm"))
+
+	names := make([]string, 0, len(s.domains))
+	for name, d := range s.domains {
+		expired := d.expires.Before(now)
+		switch {
+		case len(search) > 0 && !strings.Contains(name, search):
+			continue
+		case listType == namecheap.ListTypeExpired && !expired:
+			continue
+		case listType == namecheap.ListTypeExpiring && (expired || d.expires.After(now.AddDate(0, 0, 30))):
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sortBy := strings.ToUpper(r.Form.Get("SortBy"))
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.domains[names[i]], s.domains[names[j]]
+		switch strings.TrimSuffix(sortBy, "_DESC") {
+		case "EXPIREDATE":
+			if !a.expires.Equal(b.expires) {
+				return a.expires.Before(b.expires) != strings.HasSuffix(sortBy, "_DESC")
+			}
+		case "CREATEDATE":
+			if !a.created.Equal(b.created) {
+				return a.created.Before(b.created) != strings.HasSuffix(sortBy, "_DESC")
+			}
+		}
+		return (names[i] < names[j]) != (sortBy == "NAME_DESC")
+	})
+
+	page, pageSize := 1, 20
+	if p, err := strconv.Atoi(r.Form.Get("Page")); err == nil && p > 0 {
+		page = p
+	}
+	if p, err := strconv.Atoi(r.Form.Get("PageSize")); err == nil && p > 0 {
+		pageSize = p
+	}
+	if pageSize < 10 || pageSize > namecheap.MaxPageSize {
+		return nil, errorMessage(namecheap.ErrNumberParameterMissing, fmt.Sprintf("PageSize must be between 10 and %d", namecheap.MaxPageSize))
+	}
+
+	result := &domainListResult{}
+	for i := (page - 1) * pageSize; i < len(names) && i < page*pageSize; i++ {
+		d := s.domains[names[i]]
+		result.Domains = append(result.Domains, namecheap.Domain{
+			ID:         strconv.Itoa(d.id),
+			Name:       names[i],
+			User:       s.apiUser,
+			Created:    d.created.Format(namecheap.DateLayout),
+			Expires:    d.expires.Format(namecheap.DateLayout),
+			IsExpired:  namecheap.NewBool(d.expires.Before(now)),
+			IsLocked:   namecheap.NewBool(d.locked),
+			AutoRenew:  namecheap.NewBool(false),
+			WhoisGuard: "ENABLED",
+			IsPremium:  namecheap.NewBool(false),
+			IsOurDNS:   namecheap.NewBool(d.usingOurDNS),
+		})
+	}
+	return &domainListResponse{
+		Result: result,
+		Paging: namecheap.Paging{TotalItems: len(names), CurrentPage: page, PageSize: pageSize},
+	}, nil
+}
+
+// limited records the request and reports whether it exceeds the rate limit
+func (s *Server) limited() bool {
+	if s.rateLimit <= 0 {
+		return false
+	}
+	now := s.now()
+	recent := s.requests[:0]
+	for _, t := range s.requests {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	s.requests = recent
+	if len(s.requests) >= s.rateLimit {
+		return true
+	}
+	s.requests = append(s.requests, now)
+	return false
+}
+
+// authenticate checks the credentials, when the server was configured with some
+func (s *Server) authenticate(r *http.Request) []namecheap.Message {
+	apiUser, apiKey := r.Form.Get("ApiUser"), r.Form.Get("ApiKey")
+	switch {
+	case len(apiUser) == 0:
+		return errorMessage(namecheap.ErrNumberApiUserMissing, "Parameter APIUser is missing")
+	case len(apiKey) == 0:
+		return errorMessage(namecheap.ErrNumberApiKeyMissing, "Parameter APIKey is missing")
+	case len(s.apiKey) > 0 && (apiUser != s.apiUser || apiKey != s.apiKey):
+		return errorMessage(namecheap.ErrNumberInvalidApiKey, "API Key is invalid or API access has not been enabled")
+	}
+	return nil
+}
+
+// domainOf looks up the domain identified by the SLD and TLD parameters
+func (s *Server) domainOf(r *http.Request) (*domain, string, []namecheap.Message) {
+	sld, tld := r.Form.Get("SLD"), r.Form.Get("TLD")
+	if len(sld) == 0 {
+		return nil, "", errorMessage(namecheap.ErrNumberParameterMissing, "SLD is missing")
+	}
+	if len(tld) == 0 {
+		return nil, "", errorMessage(namecheap.ErrNumberParameterMissing, "TLD is missing")
+	}
+	name := strings.ToLower(sld + "." + tld)
+	d, ok := s.domains[name]
+	if !ok {
+		return nil, "", errorMessage(namecheap.ErrNumberDomainNotFound, "Domain name not found")
+	}
+	return d, name, nil
+}
+
+// hostsFromForm reads the indexed HostNameN, RecordTypeN, AddressN... parameters of setHosts
+func hostsFromForm(r *http.Request) ([]namecheap.Host, []namecheap.Message) {
+	var indexes []int
+	for key := range r.Form {
+		if !strings.HasPrefix(key, "HostName") {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(key, "HostName"))
+		if err != nil {
+			return nil, errorMessage(namecheap.ErrNumberInvalidHostRecord, fmt.Sprintf("Invalid parameter %s", key))
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	hosts := make([]namecheap.Host, 0, len(indexes))
+	for _, i := range indexes {
+		n := strconv.Itoa(i)
+		host := namecheap.Host{
+			Name:         r.Form.Get("HostName" + n),
+			Type:         namecheap.RecordType(strings.ToUpper(r.Form.Get("RecordType" + n))),
+			Address:      r.Form.Get("Address" + n),
+			FriendlyName: r.Form.Get("FriendlyName" + n),
+		}
+		if len(host.Type) == 0 || len(host.Address) == 0 {
+			return nil, errorMessage(namecheap.ErrNumberInvalidHostRecord, fmt.Sprintf("RecordType%s and Address%s are required", n, n))
+		}
+		var err error
+		if host.MXPref, err = namecheap.ParseInt(r.Form.Get("MXPref" + n)); err != nil {
+			return nil, errorMessage(namecheap.ErrNumberInvalidHostRecord, fmt.Sprintf("Invalid MXPref%s: %v", n, err))
+		}
+		if host.TTL, err = namecheap.ParseInt(r.Form.Get("TTL" + n)); err != nil {
+			return nil, errorMessage(namecheap.ErrNumberInvalidHostRecord, fmt.Sprintf("Invalid TTL%s: %v", n, err))
+		}
+		if host.IsActive, err = namecheap.ParseBool(r.Form.Get("IsActive" + n)); err != nil {
+			return nil, errorMessage(namecheap.ErrNumberInvalidHostRecord, fmt.Sprintf("Invalid IsActive%s: %v", n, err))
+		}
+		if host.Type == namecheap.RecordTypeCAA {
+			host.Address = fmt.Sprintf("%s %s \"%s\"", r.Form.Get("Flag"+n), r.Form.Get("Tag"+n), host.Address)
+		}
+		if !host.TTL.Valid {
+			host.TTL = namecheap.NewInt(1800)
+		}
+		if !host.MXPref.Valid {
+			host.MXPref = namecheap.NewInt(10)
+		}
+		if !host.IsActive.Valid {
+			host.IsActive = namecheap.NewBool(true)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// setHosts replaces the hosts, assigning new HostIds like the real API does
+func (d *domain) setHosts(hosts []namecheap.Host) {
+	d.hosts = make([]namecheap.Host, 0, len(hosts))
+	for _, h := range hosts {
+		if h.IsEmpty() {
+			continue
+		}
+		h.HostId = strconv.Itoa(d.nextHostId)
+		h.IsDDNSEnabled = namecheap.NewBool(false)
+		d.nextHostId++
+		d.hosts = append(d.hosts, h)
+	}
+}
+
+// errorMessage returns a single API error
+func errorMessage(number, text string) []namecheap.Message {
+	return []namecheap.Message{{Number: number, Text: text}}
+}
+
+// response is the envelope of every answer
+type response struct {
+	XMLName xml.Name `xml:"ApiResponse"`
+	Status  string   `xml:"Status,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Errors  struct {
+		Error []namecheap.Message `xml:"Error"`
+	} `xml:"Errors"`
+	Warnings          struct{}         `xml:"Warnings"`
+	RequestedCommand  string           `xml:"RequestedCommand"`
+	CommandResponse   *commandResponse `xml:"CommandResponse,omitempty"`
+	Server            string           `xml:"Server"`
+	GMTTimeDifference string           `xml:"GMTTimeDifference"`
+	ExecutionTime     string           `xml:"ExecutionTime"`
+}
+
+type commandResponse struct {
+	Type   string      `xml:"Type,attr"`
+	Result interface{} `xml:",any"`
+}
+
+type getHostsResult struct {
+	XMLName       xml.Name         `xml:"DomainDNSGetHostsResult"`
+	Domain        string           `xml:"Domain,attr"`
+	EmailType     string           `xml:"EmailType,attr"`
+	IsUsingOurDNS string           `xml:"IsUsingOurDNS,attr"`
+	Hosts         []namecheap.Host `xml:"host"`
+}
+
+// domainListResponse holds both elements domains.getList returns inside CommandResponse
+type domainListResponse struct {
+	Result *domainListResult `xml:"DomainGetListResult"`
+	Paging namecheap.Paging  `xml:"Paging"`
+}
+
+// MarshalXML writes both elements without a wrapper
+func (r *domainListResponse) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	if err := e.EncodeElement(r.Result, xml.StartElement{Name: xml.Name{Local: "DomainGetListResult"}}); err != nil {
+		return err
+	}
+	return e.EncodeElement(r.Paging, xml.StartElement{Name: xml.Name{Local: "Paging"}})
+}
+
+type domainListResult struct {
+	Domains []namecheap.Domain `xml:"Domain"`
+}
+
+type domainInfoResult struct {
+	XMLName xml.Name `xml:"DomainGetInfoResult"`
+	namecheap.DomainInfo
+}
+
+type registrarLockResult struct {
+	XMLName             xml.Name `xml:"DomainGetRegistrarLockResult"`
+	Domain              string   `xml:"Domain,attr"`
+	RegistrarLockStatus string   `xml:"RegistrarLockStatus,attr"`
+}
+
+type nameserversResult struct {
+	XMLName xml.Name `xml:"DomainDNSGetListResult"`
+	namecheap.Nameservers
+}
+
+type setNameserversResult struct {
+	XMLName xml.Name
+	Domain  string `xml:"Domain,attr"`
+	Updated string `xml:"Updated,attr"`
+}
+
+type emailForwardingResult struct {
+	XMLName  xml.Name                 `xml:"DomainDNSGetEmailForwardingResult"`
+	Domain   string                   `xml:"Domain,attr"`
+	Forwards []namecheap.EmailForward `xml:"Forward"`
+}
+
+type setEmailForwardingResult struct {
+	XMLName   xml.Name `xml:"DomainDNSSetEmailForwardingResult"`
+	Domain    string   `xml:"Domain,attr"`
+	IsSuccess string   `xml:"IsSuccess,attr"`
+}
+
+type setHostsResult struct {
+	XMLName   xml.Name `xml:"DomainDNSSetHostsResult"`
+	Domain    string   `xml:"Domain,attr"`
+	IsSuccess string   `xml:"IsSuccess,attr"`
+}
@@ -0,0 +1,131 @@
+package mockserver
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+// newClient starts s and returns a client of it
+func newClient(t *testing.T, s *Server, apiUser, apiKey string) *namecheap.Client {
+	t.Helper()
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	client, err := namecheap.NewClient(
+		namecheap.WithCredentials(apiUser, apiKey),
+		namecheap.WithBaseURL(server.URL),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestGetSetHosts(t *testing.T) {
+	s := New(
+		WithCredentials("user", "key"),
+		WithDomain("example.com", "MX", namecheap.Host{Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.1", TTL: namecheap.NewInt(1799)}),
+	)
+	client := newClient(t, s, "user", "key")
+	ctx := context.Background()
+
+	response, err := client.GetHosts(ctx, "example", "com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := response.CommandResponse.DomainDNSGetHostsResult
+	if result.Domain != "example.com" || result.EmailType != "MX" || len(result.Host) != 1 || result.Host[0].HostId != "1" || result.Host[0].Address != "192.0.2.1" {
+		t.Fatalf("GetHosts() = %+v", result)
+	}
+
+	hosts := []namecheap.Host{
+		{HostId: "1", Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.2", TTL: namecheap.NewInt(300)},
+		{HostId: "2", Name: "@", Type: namecheap.RecordTypeCAA, Address: "letsencrypt.org", Tag: "issue"},
+		{HostId: "3", Name: "www", Type: namecheap.RecordTypeCNAME, Address: "example.com."},
+	}
+	if _, err := client.SetHosts(ctx, "example", "com", hosts, "fwd"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []namecheap.Host{
+		{HostId: "2", Name: "@", Type: namecheap.RecordTypeA, Address: "192.0.2.2", TTL: namecheap.NewInt(300)},
+		{HostId: "3", Name: "@", Type: namecheap.RecordTypeCAA, Address: `0 issue "letsencrypt.org"`},
+		{HostId: "4", Name: "www", Type: namecheap.RecordTypeCNAME, Address: "example.com."},
+	}
+	got := s.Hosts("example.com")
+	if diff := namecheap.DiffHosts(got, want); !diff.IsEmpty() {
+		t.Errorf("hosts after SetHosts differ:\n%s", diff)
+	}
+	for i, h := range got {
+		if h.HostId != want[i].HostId {
+			t.Errorf("host %d got HostId %s, want a new one %s", i, h.HostId, want[i].HostId)
+		}
+		if h.MXPref != namecheap.NewInt(10) || h.IsActive != namecheap.NewBool(true) {
+			t.Errorf("host %d has no defaults: %+v", i, h)
+		}
+	}
+
+	response, err = client.GetHosts(ctx, "example", "com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emailType := response.CommandResponse.DomainDNSGetHostsResult.EmailType; emailType != "FWD" {
+		t.Errorf("email type = %q, want %q", emailType, "FWD")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := New(WithCredentials("user", "key"), WithDomain("example.com", "MX"))
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		client *namecheap.Client
+		sld    string
+		number string
+	}{
+		{"wrong key", newClient(t, s, "user", "other"), "example", namecheap.ErrNumberInvalidApiKey},
+		{"wrong user", newClient(t, s, "other", "key"), "example", namecheap.ErrNumberInvalidApiKey},
+		{"unknown domain", newClient(t, s, "user", "key"), "missing", namecheap.ErrNumberDomainNotFound},
+	}
+	for _, tt := range tests {
+		_, err := tt.client.GetHosts(ctx, tt.sld, "com")
+		var apiErr *namecheap.APIError
+		if !errors.As(err, &apiErr) || !apiErr.HasNumber(tt.number) {
+			t.Errorf("%s: GetHosts() error = %v, want API error %s", tt.name, err, tt.number)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Date(2023, 2, 16, 9, 0, 0, 0, time.UTC)
+	s := New(WithRateLimit(2), WithDomain("example.com", "MX"))
+	s.now = func() time.Time { return now }
+	client := newClient(t, s, "user", "key")
+	ctx := context.Background()
+
+	steps := []struct {
+		advance time.Duration
+		limited bool
+	}{
+		{0, false},
+		{10 * time.Second, false},
+		{10 * time.Second, true},
+		// the first request is more than a minute old
+		{45 * time.Second, false},
+		{0, true},
+		{time.Minute, false},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		_, err := client.GetHosts(ctx, "example", "com")
+		var apiErr *namecheap.APIError
+		if limited := errors.As(err, &apiErr) && apiErr.HasNumber(namecheap.ErrNumberTooManyRequests); limited != step.limited || (err != nil && !limited) {
+			t.Fatalf("step %d: GetHosts() error = %v, want rate limited %v", i, err, step.limited)
+		}
+	}
+}
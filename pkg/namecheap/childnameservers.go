@@ -0,0 +1,116 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ChildNameserverResult is the result of the domains.ns commands. XMLName keeps the element of each command, e.g.: DomainNSCreateResult
+type ChildNameserverResult struct {
+	XMLName    xml.Name
+	Domain     string              `xml:"Domain,attr"`
+	Nameserver string              `xml:"Nameserver,attr"`
+	IP         string              `xml:"IP,attr,omitempty" yaml:",omitempty" json:",omitempty"`
+	IsSuccess  Bool                `xml:"IsSuccess,attr" yaml:",omitempty"`
+	Statuses   *NameserverStatuses `xml:"NameserverStatuses,omitempty" yaml:",omitempty" json:",omitempty"`
+}
+
+// NameserverStatuses are the registry statuses of a child nameserver, e.g.: 'ok', 'linked'
+type NameserverStatuses struct {
+	Status []string `xml:"Status"`
+}
+
+// ChildNameserverResponse is the response of the domains.ns commands
+type ChildNameserverResponse struct {
+	XMLName         xml.Name `xml:"ApiResponse"`
+	Status          string   `xml:"Status,attr"`
+	CommandResponse struct {
+		Type   string                `xml:"Type,attr"`
+		Result ChildNameserverResult `xml:",any"`
+	} `xml:"CommandResponse"`
+	ExecutionTime string `xml:"ExecutionTime"`
+}
+
+// CreateNameserver registers nameserver, e.g.: 'ns1.example.com', with its glue IP at the registry of sld.tld
+func (c *Client) CreateNameserver(ctx context.Context, sld, tld, nameserver, ip string) (*ChildNameserverResponse, error) {
+	params, err := childNameserverParams(sld, tld, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateGlueIP(ip); err != nil {
+		return nil, err
+	}
+	params.Set("IP", ip)
+	return c.callChildNameserver(ctx, "domains.ns.create", params)
+}
+
+// DeleteNameserver removes nameserver from the registry of sld.tld
+func (c *Client) DeleteNameserver(ctx context.Context, sld, tld, nameserver string) (*ChildNameserverResponse, error) {
+	params, err := childNameserverParams(sld, tld, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	return c.callChildNameserver(ctx, "domains.ns.delete", params)
+}
+
+// GetNameserverInfo returns the glue IP and statuses of nameserver
+func (c *Client) GetNameserverInfo(ctx context.Context, sld, tld, nameserver string) (*ChildNameserverResponse, error) {
+	params, err := childNameserverParams(sld, tld, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	return c.callChildNameserver(ctx, "domains.ns.getInfo", params)
+}
+
+// UpdateNameserver changes the glue IP of nameserver from oldIP to ip
+func (c *Client) UpdateNameserver(ctx context.Context, sld, tld, nameserver, oldIP, ip string) (*ChildNameserverResponse, error) {
+	params, err := childNameserverParams(sld, tld, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range []string{oldIP, ip} {
+		if err := validateGlueIP(address); err != nil {
+			return nil, err
+		}
+	}
+	params.Set("OldIP", oldIP)
+	params.Set("IP", ip)
+	return c.callChildNameserver(ctx, "domains.ns.update", params)
+}
+
+// callChildNameserver performs one of the domains.ns commands
+func (c *Client) callChildNameserver(ctx context.Context, command string, params url.Values) (*ChildNameserverResponse, error) {
+	response := &ChildNameserverResponse{}
+	if err := c.call(ctx, command, params, nil, response); err != nil {
+		return nil, err
+	}
+	// the namespace of the envelope is inherited, it does not need repeating when written back
+	response.CommandResponse.Result.XMLName.Space = ""
+	return response, nil
+}
+
+// childNameserverParams returns the query parameters of the domains.ns commands, checking that nameserver is under sld.tld
+func childNameserverParams(sld, tld, nameserver string) (url.Values, error) {
+	nameserver = strings.ToLower(strings.TrimSuffix(nameserver, "."))
+	if err := validateTarget(nameserver); err != nil {
+		return nil, fmt.Errorf("nameserver: %w", err)
+	}
+	if domain := strings.ToLower(sld + "." + tld); !strings.HasSuffix(nameserver, "."+domain) {
+		return nil, fmt.Errorf("nameserver '%s' is not under '%s', e.g.: 'ns1.%s'", nameserver, domain, domain)
+	}
+	params := domainParams(sld, tld)
+	params.Set("Nameserver", nameserver)
+	return params, nil
+}
+
+// validateGlueIP checks an IPv4 glue address
+func validateGlueIP(ip string) error {
+	if address := net.ParseIP(ip); address == nil || address.To4() == nil {
+		return fmt.Errorf("'%s' is not an IPv4 address", ip)
+	}
+	return nil
+}
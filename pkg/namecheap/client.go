@@ -0,0 +1,325 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the production Namecheap API endpoint
+	DefaultBaseURL = "https://api.namecheap.com/xml.response"
+	// SandboxBaseURL is the sandbox Namecheap API endpoint
+	SandboxBaseURL = "https://api.sandbox.namecheap.com/xml.response"
+	// DefaultClientIP is sent when no client IP is configured. Namecheap does not really check it
+	DefaultClientIP = "127.0.0.1"
+	// DefaultTimeout is the per request timeout used when none is configured
+	DefaultTimeout = 10 * time.Second
+
+	commandPrefix = "namecheap."
+	// redacted replaces secrets in logged URLs, raw responses and errors
+	redacted = "REDACTED"
+)
+
+// Client talks to the Namecheap XML API. It is safe for concurrent use
+type Client struct {
+	apiUser    string
+	apiKey     string
+	username   string
+	clientIP   string
+	sandbox    bool
+	baseURL    string
+	timeout    time.Duration
+	httpClient *http.Client
+	limiter    *RateLimiter
+	retry      RetryPolicy
+	debugf     func(format string, args ...interface{})
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the API user and key. The username defaults to the API user
+func WithCredentials(apiUser, apiKey string) Option {
+	return func(c *Client) {
+		c.apiUser = apiUser
+		c.apiKey = apiKey
+	}
+}
+
+// WithUsername sets the user the commands are executed for, when different from the API user
+func WithUsername(username string) Option {
+	return func(c *Client) {
+		c.username = username
+	}
+}
+
+// WithSandbox switches the client to the sandbox API, unless a base URL is set explicitly
+func WithSandbox(sandbox bool) Option {
+	return func(c *Client) {
+		c.sandbox = sandbox
+	}
+}
+
+// WithClientIP sets the client IP sent with every request
+func WithClientIP(clientIP string) Option {
+	return func(c *Client) {
+		c.clientIP = clientIP
+	}
+}
+
+// WithBaseURL overrides the API endpoint
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the http.Client used to perform requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the per request timeout. Zero disables it
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRateLimiter makes every request wait for limiter first
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithDebugLogger sets a function receiving debug messages, like request URLs and raw responses
+func WithDebugLogger(debugf func(format string, args ...interface{})) Option {
+	return func(c *Client) {
+		c.debugf = debugf
+	}
+}
+
+// NewClient returns a Client configured with the given options
+func NewClient(opts ...Option) (*Client, error) {
+	c := &Client{
+		clientIP: DefaultClientIP,
+		timeout:  DefaultTimeout,
+		retry:    DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if len(c.apiUser) == 0 || len(c.apiKey) == 0 {
+		return nil, ErrMissingCredentials
+	}
+	if len(c.username) == 0 {
+		c.username = c.apiUser
+	}
+	if len(c.baseURL) == 0 {
+		c.baseURL = DefaultBaseURL
+		if c.sandbox {
+			c.baseURL = SandboxBaseURL
+		}
+	}
+	if _, err := url.Parse(c.baseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL '%s': %w", c.baseURL, err)
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{}
+	}
+	if c.debugf == nil {
+		c.debugf = func(string, ...interface{}) {}
+	}
+
+	return c, nil
+}
+
+// GetHosts downloads the DNS host records of sld.tld
+func (c *Client) GetHosts(ctx context.Context, sld, tld string) (*ApiResponse, error) {
+	response := &ApiResponse{}
+	err := c.call(ctx, "domains.dns.getHosts", domainParams(sld, tld), nil, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SetHosts replaces all DNS host records of sld.tld with hosts and sets the email type, when not empty.
+// Each host is indexed by its HostId, hosts without one are skipped
+func (c *Client) SetHosts(ctx context.Context, sld, tld string, hosts []Host, emailType string) (*ApiResponse, error) {
+	body := url.Values{}
+	if len(emailType) > 0 {
+		if !isEmailType(emailType) {
+			return nil, fmt.Errorf("email type '%s' is not one of %v", emailType, EmailTypes)
+		}
+		body.Set("EmailType", strings.ToUpper(emailType))
+	}
+	for _, host := range hosts {
+		if len(host.HostId) == 0 {
+			continue
+		}
+		address := host.Address
+		if strings.EqualFold(string(host.Type), string(RecordTypeCAA)) {
+			flag, tag, value, err := host.CAA()
+			if err != nil {
+				return nil, err
+			}
+			body.Set("Flag"+host.HostId, flag)
+			body.Set("Tag"+host.HostId, tag)
+			address = value
+		}
+		body.Set("HostName"+host.HostId, host.Name)
+		body.Set("RecordType"+host.HostId, string(host.Type))
+		body.Set("Address"+host.HostId, address)
+		body.Set("MXPref"+host.HostId, host.MXPref.String())
+		body.Set("TTL"+host.HostId, host.TTL.String())
+		body.Set("FriendlyName"+host.HostId, host.FriendlyName)
+		body.Set("IsActive"+host.HostId, host.IsActive.String())
+	}
+
+	response := &ApiResponse{}
+	err := c.call(ctx, "domains.dns.setHosts", domainParams(sld, tld), body, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// isEmailType reports whether emailType is one of EmailTypes
+func isEmailType(emailType string) bool {
+	for _, t := range EmailTypes {
+		if strings.EqualFold(t, emailType) {
+			return true
+		}
+	}
+	return false
+}
+
+// domainParams returns the query parameters identifying a domain
+func domainParams(sld, tld string) url.Values {
+	return url.Values{
+		"SLD": {sld},
+		"TLD": {tld},
+	}
+}
+
+// call performs the request, retrying transient errors, checks the response status and unmarshals the response into v
+func (c *Client) call(ctx context.Context, command string, params url.Values, body url.Values, v interface{}) error {
+	return c.callWithRetry(ctx, command, func() error {
+		return c.callOnce(ctx, command, params, body, v)
+	})
+}
+
+// callOnce performs a single attempt of call
+func (c *Client) callOnce(ctx context.Context, command string, params url.Values, body url.Values, v interface{}) error {
+	query := url.Values{}
+	for k, values := range params {
+		query[k] = values
+	}
+	query.Set("ApiUser", c.apiUser)
+	query.Set("ApiKey", c.apiKey)
+	query.Set("UserName", c.username)
+	query.Set("ClientIp", c.clientIP)
+	query.Set("Command", commandPrefix+command)
+	requestURL := c.baseURL + "?" + query.Encode()
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("waiting for the rate limiter: %w", err)
+		}
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var (
+		req *http.Request
+		err error
+	)
+	if body != nil {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, requestURL, strings.NewReader(body.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		if err == nil {
+			req.Header.Set("Cache-Control", "no-cache")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	c.debugf("%s %s", req.Method, c.redact(requestURL))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// the URL carried by the error holds the API key
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = c.redact(urlErr.URL)
+		}
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	c.debugf("Raw response: \n%s", c.redact(string(raw)))
+
+	if resp.StatusCode != http.StatusOK {
+		httpErr := &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			httpErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return httpErr
+	}
+
+	status := &statusEnvelope{}
+	if err := xml.Unmarshal(raw, status); err != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	if status.Status != "OK" {
+		return &APIError{Command: commandPrefix + command, Errors: status.Errors.Error}
+	}
+
+	if err := xml.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return nil
+}
+
+// redact replaces every occurrence of the API key in s, raw or query escaped
+func (c *Client) redact(s string) string {
+	if len(c.apiKey) == 0 {
+		return s
+	}
+	return strings.NewReplacer(
+		c.apiKey, redacted,
+		url.QueryEscape(c.apiKey), redacted,
+	).Replace(s)
+}
+
+// statusEnvelope holds the parts common to every API response
+type statusEnvelope struct {
+	XMLName xml.Name `xml:"ApiResponse"`
+	Status  string   `xml:"Status,attr"`
+	Errors  struct {
+		Error []Message `xml:"Error"`
+	} `xml:"Errors"`
+}
@@ -0,0 +1,163 @@
+package namecheap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HostChange is a host whose key stayed the same while some other attribute changed
+type HostChange struct {
+	Before Host `json:"before" yaml:"before"`
+	After  Host `json:"after" yaml:"after"`
+}
+
+// EmailTypeChange holds the email type before and after a change
+type EmailTypeChange struct {
+	Before string `json:"before" yaml:"before"`
+	After  string `json:"after" yaml:"after"`
+}
+
+// HostsDiff holds the record level differences between two sets of hosts
+type HostsDiff struct {
+	Added     []Host           `json:"added" yaml:"added"`
+	Removed   []Host           `json:"removed" yaml:"removed"`
+	Changed   []HostChange     `json:"changed" yaml:"changed"`
+	EmailType *EmailTypeChange `json:"emailType,omitempty" yaml:"emailType,omitempty"`
+}
+
+// Key identifies a host by name, type and address
+func (h Host) Key() string {
+	return strings.Join([]string{strings.ToLower(h.Name), strings.ToUpper(string(h.Type)), h.Address}, "|")
+}
+
+// IsEmpty reports whether the host carries no record, e.g. after being cleared for deletion
+func (h Host) IsEmpty() bool {
+	return len(h.Name) == 0 && len(h.Type) == 0 && len(h.Address) == 0
+}
+
+// String renders the host on a single line
+func (h Host) String() string {
+	s := fmt.Sprintf("%s %s %s", h.Name, h.Type, h.Address)
+	if h.MXPref.Valid && h.isType(RecordTypeMX) {
+		s = fmt.Sprintf("%s mxpref=%s", s, h.MXPref)
+	}
+	if h.TTL.Valid {
+		s = fmt.Sprintf("%s ttl=%s", s, h.TTL)
+	}
+	if len(h.FriendlyName) > 0 {
+		s = fmt.Sprintf("%s friendlyname=%q", s, h.FriendlyName)
+	}
+	if h.IsActive.IsFalse() {
+		s += " inactive"
+	}
+	return s
+}
+
+// isType reports whether the host has type t, in any case
+func (h Host) isType(t RecordType) bool {
+	return strings.EqualFold(string(h.Type), string(t))
+}
+
+// DiffHosts computes which records must be added, removed or changed to turn current into desired.
+// Hosts are matched by Key. Attributes left empty in desired are considered unchanged
+func DiffHosts(current, desired []Host) *HostsDiff {
+	diff := &HostsDiff{}
+
+	remaining := map[string][]Host{}
+	for _, h := range current {
+		if h.IsEmpty() {
+			continue
+		}
+		remaining[h.Key()] = append(remaining[h.Key()], h)
+	}
+
+	for _, h := range desired {
+		if h.IsEmpty() {
+			continue
+		}
+		matches := remaining[h.Key()]
+		if len(matches) == 0 {
+			diff.Added = append(diff.Added, h)
+			continue
+		}
+		before := matches[0]
+		remaining[h.Key()] = matches[1:]
+		if !sameAttributes(before, h) {
+			diff.Changed = append(diff.Changed, HostChange{Before: before, After: h})
+		}
+	}
+
+	for _, hosts := range remaining {
+		diff.Removed = append(diff.Removed, hosts...)
+	}
+
+	sortHosts(diff.Added)
+	sortHosts(diff.Removed)
+	sort.SliceStable(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].After.Key() < diff.Changed[j].After.Key()
+	})
+
+	return diff
+}
+
+// DiffEmailType returns the change from current to desired, or nil when desired is empty or the same
+func DiffEmailType(current, desired string) *EmailTypeChange {
+	if len(desired) == 0 || strings.EqualFold(current, desired) {
+		return nil
+	}
+	return &EmailTypeChange{Before: current, After: desired}
+}
+
+// IsEmpty reports whether there are no differences
+func (d *HostsDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && d.EmailType == nil
+}
+
+// Summary returns a short count of the differences
+func (d *HostsDiff) Summary() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to remove", len(d.Added), len(d.Changed), len(d.Removed))
+}
+
+// String renders the differences for humans, one record per line
+func (d *HostsDiff) String() string {
+	var b strings.Builder
+	if d.EmailType != nil {
+		fmt.Fprintf(&b, "~ EmailType %s\n    -> %s\n", d.EmailType.Before, d.EmailType.After)
+	}
+	for _, h := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", h)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~ %s\n    -> %s\n", c.Before, c.After)
+	}
+	for _, h := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", h)
+	}
+	fmt.Fprintf(&b, "Plan: %s", d.Summary())
+	return b.String()
+}
+
+// sameAttributes compares the attributes not part of the key
+func sameAttributes(before, after Host) bool {
+	if after.isType(RecordTypeMX) && after.MXPref.Valid && after.MXPref != before.MXPref {
+		return false
+	}
+	if after.TTL.Valid && after.TTL != before.TTL {
+		return false
+	}
+	if len(after.FriendlyName) > 0 && after.FriendlyName != before.FriendlyName {
+		return false
+	}
+	if after.IsActive.Valid && after.IsActive != before.IsActive {
+		return false
+	}
+	return true
+}
+
+// sortHosts orders hosts by key for a stable output
+func sortHosts(hosts []Host) {
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i].Key() < hosts[j].Key()
+	})
+}
@@ -0,0 +1,66 @@
+package namecheap
+
+import (
+	"testing"
+)
+
+func TestDiffHosts(t *testing.T) {
+	a := Host{HostId: "1", Name: "@", Type: RecordTypeA, Address: "192.0.2.1", TTL: NewInt(1799)}
+	www := Host{HostId: "2", Name: "www", Type: RecordTypeCNAME, Address: "example.com.", TTL: NewInt(1799)}
+	mx := Host{HostId: "3", Name: "@", Type: RecordTypeMX, Address: "mail.example.com.", MXPref: NewInt(10), TTL: NewInt(1799)}
+
+	withTTL := func(h Host, ttl int) Host {
+		h.TTL = NewInt(ttl)
+		return h
+	}
+	withId := func(h Host, id string) Host {
+		h.HostId = id
+		return h
+	}
+
+	tests := []struct {
+		name                    string
+		current, desired        []Host
+		added, changed, removed int
+		wantEmpty               bool
+	}{
+		{name: "same", current: []Host{a, www}, desired: []Host{a, www}, wantEmpty: true},
+		{name: "host ids are ignored", current: []Host{a, www}, desired: []Host{withId(www, "7"), withId(a, "8")}, wantEmpty: true},
+		{name: "case of name and type is ignored", current: []Host{a}, desired: []Host{{Name: "@", Type: "a", Address: "192.0.2.1"}}, wantEmpty: true},
+		{name: "unset attributes are not changes", current: []Host{a}, desired: []Host{{Name: "@", Type: RecordTypeA, Address: "192.0.2.1"}}, wantEmpty: true},
+		{name: "added", current: []Host{a}, desired: []Host{a, www}, added: 1},
+		{name: "removed", current: []Host{a, www, mx}, desired: []Host{a}, removed: 2},
+		{name: "ttl changed", current: []Host{a, www}, desired: []Host{withTTL(a, 300), www}, changed: 1},
+		{name: "mx preference changed", current: []Host{mx}, desired: []Host{{Name: "@", Type: RecordTypeMX, Address: "mail.example.com.", MXPref: NewInt(20)}}, changed: 1},
+		{name: "address changed is a replacement", current: []Host{a}, desired: []Host{{Name: "@", Type: RecordTypeA, Address: "192.0.2.2"}}, added: 1, removed: 1},
+		{name: "empty hosts are ignored", current: []Host{a, {}}, desired: []Host{{}, a}, wantEmpty: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffHosts(tt.current, tt.desired)
+			if diff.IsEmpty() != tt.wantEmpty {
+				t.Fatalf("IsEmpty() = %v, want %v:\n%s", diff.IsEmpty(), tt.wantEmpty, diff)
+			}
+			if len(diff.Added) != tt.added || len(diff.Changed) != tt.changed || len(diff.Removed) != tt.removed {
+				t.Errorf("got %s, want %d to add, %d to change, %d to remove:\n%s", diff.Summary(), tt.added, tt.changed, tt.removed, diff)
+			}
+		})
+	}
+}
+
+func TestDiffEmailType(t *testing.T) {
+	tests := []struct {
+		current, desired string
+		want             bool
+	}{
+		{"MX", "", false},
+		{"MX", "mx", false},
+		{"MX", "FWD", true},
+		{"", "MX", true},
+	}
+	for _, tt := range tests {
+		if got := DiffEmailType(tt.current, tt.desired) != nil; got != tt.want {
+			t.Errorf("DiffEmailType(%q, %q) changed = %v, want %v", tt.current, tt.desired, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the format of dates returned by the domains commands, e.g.: '02/15/2024'
+const DateLayout = "01/02/2006"
+
+// List types accepted by domains.getList
+const (
+	ListTypeAll      = "ALL"
+	ListTypeExpiring = "EXPIRING"
+	ListTypeExpired  = "EXPIRED"
+)
+
+// MaxPageSize is the largest page domains.getList returns
+const MaxPageSize = 100
+
+// ListTypes are the values accepted for DomainListOptions.ListType
+var ListTypes = []string{ListTypeAll, ListTypeExpiring, ListTypeExpired}
+
+// SortOrders are the values accepted for DomainListOptions.SortBy
+var SortOrders = []string{"NAME", "NAME_DESC", "EXPIREDATE", "EXPIREDATE_DESC", "CREATEDATE", "CREATEDATE_DESC"}
+
+// Domain is a domain of the account, as returned by domains.getList
+type Domain struct {
+	ID         string `xml:"ID,attr"`
+	Name       string `xml:"Name,attr"`
+	User       string `xml:"User,attr"`
+	Created    string `xml:"Created,attr"`
+	Expires    string `xml:"Expires,attr"`
+	IsExpired  Bool   `xml:"IsExpired,attr" yaml:",omitempty"`
+	IsLocked   Bool   `xml:"IsLocked,attr" yaml:",omitempty"`
+	AutoRenew  Bool   `xml:"AutoRenew,attr" yaml:",omitempty"`
+	WhoisGuard string `xml:"WhoisGuard,attr"`
+	IsPremium  Bool   `xml:"IsPremium,attr" yaml:",omitempty"`
+	IsOurDNS   Bool   `xml:"IsOurDNS,attr" yaml:",omitempty"`
+}
+
+// ExpiresAt parses the expiry date
+func (d Domain) ExpiresAt() (time.Time, error) {
+	return time.Parse(DateLayout, d.Expires)
+}
+
+// Paging describes the page of a list response
+type Paging struct {
+	TotalItems  int `xml:"TotalItems"`
+	CurrentPage int `xml:"CurrentPage"`
+	PageSize    int `xml:"PageSize"`
+}
+
+// DomainListResponse is the response of domains.getList
+type DomainListResponse struct {
+	XMLName         xml.Name `xml:"ApiResponse"`
+	Status          string   `xml:"Status,attr"`
+	CommandResponse struct {
+		Type                string `xml:"Type,attr"`
+		DomainGetListResult struct {
+			Domain []Domain `xml:"Domain"`
+		} `xml:"DomainGetListResult"`
+		Paging Paging `xml:"Paging"`
+	} `xml:"CommandResponse"`
+	ExecutionTime string `xml:"ExecutionTime"`
+}
+
+// DomainListOptions filter and order domains.getList. Zero values use the API defaults
+type DomainListOptions struct {
+	ListType   string
+	SearchTerm string
+	SortBy     string
+	Page       int
+	PageSize   int
+}
+
+// GetDomainList returns one page of the domains in the account
+func (c *Client) GetDomainList(ctx context.Context, opts DomainListOptions) (*DomainListResponse, error) {
+	params := url.Values{}
+	if len(opts.ListType) > 0 {
+		if !containsFold(ListTypes, opts.ListType) {
+			return nil, fmt.Errorf("list type '%s' is not one of %v", opts.ListType, ListTypes)
+		}
+		params.Set("ListType", strings.ToUpper(opts.ListType))
+	}
+	if len(opts.SearchTerm) > 0 {
+		params.Set("SearchTerm", opts.SearchTerm)
+	}
+	if len(opts.SortBy) > 0 {
+		if !containsFold(SortOrders, opts.SortBy) {
+			return nil, fmt.Errorf("sort order '%s' is not one of %v", opts.SortBy, SortOrders)
+		}
+		params.Set("SortBy", strings.ToUpper(opts.SortBy))
+	}
+	if opts.Page > 0 {
+		params.Set("Page", strconv.Itoa(opts.Page))
+	}
+	if opts.PageSize > 0 {
+		params.Set("PageSize", strconv.Itoa(opts.PageSize))
+	}
+
+	response := &DomainListResponse{}
+	if err := c.call(ctx, "domains.getList", params, nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListDomains returns the domains in the account. When opts.Page is zero, all pages are fetched
+func (c *Client) ListDomains(ctx context.Context, opts DomainListOptions) ([]Domain, error) {
+	if opts.Page > 0 {
+		response, err := c.GetDomainList(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return response.CommandResponse.DomainGetListResult.Domain, nil
+	}
+
+	if opts.PageSize == 0 {
+		opts.PageSize = MaxPageSize
+	}
+	var domains []Domain
+	for opts.Page = 1; ; opts.Page++ {
+		response, err := c.GetDomainList(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		page := response.CommandResponse.DomainGetListResult.Domain
+		domains = append(domains, page...)
+		if len(page) == 0 || len(domains) >= response.CommandResponse.Paging.TotalItems {
+			return domains, nil
+		}
+	}
+}
+
+// DomainInfo is the result of domains.getInfo
+type DomainInfo struct {
+	Status        string `xml:"Status,attr"`
+	ID            string `xml:"ID,attr"`
+	DomainName    string `xml:"DomainName,attr"`
+	OwnerName     string `xml:"OwnerName,attr"`
+	IsOwner       Bool   `xml:"IsOwner,attr" yaml:",omitempty"`
+	IsPremium     Bool   `xml:"IsPremium,attr" yaml:",omitempty"`
+	DomainDetails struct {
+		CreatedDate string `xml:"CreatedDate"`
+		ExpiredDate string `xml:"ExpiredDate"`
+		NumYears    int    `xml:"NumYears"`
+	} `xml:"DomainDetails"`
+	Whoisguard struct {
+		Enabled     string `xml:"Enabled,attr"`
+		ID          string `xml:"ID"`
+		ExpiredDate string `xml:"ExpiredDate"`
+	} `xml:"Whoisguard"`
+	DnsDetails struct {
+		ProviderType     string   `xml:"ProviderType,attr"`
+		IsUsingOurDNS    Bool     `xml:"IsUsingOurDNS,attr" yaml:",omitempty"`
+		HostCount        int      `xml:"HostCount,attr"`
+		EmailType        string   `xml:"EmailType,attr"`
+		DynamicDNSStatus Bool     `xml:"DynamicDNSStatus,attr" yaml:",omitempty"`
+		IsFailover       Bool     `xml:"IsFailover,attr" yaml:",omitempty"`
+		Nameserver       []string `xml:"Nameserver"`
+	} `xml:"DnsDetails"`
+}
+
+// ExpiresAt parses the expiry date
+func (i DomainInfo) ExpiresAt() (time.Time, error) {
+	return time.Parse(DateLayout, i.DomainDetails.ExpiredDate)
+}
+
+// domainInfoResponse is the response of domains.getInfo
+type domainInfoResponse struct {
+	XMLName         xml.Name `xml:"ApiResponse"`
+	CommandResponse struct {
+		DomainGetInfoResult DomainInfo `xml:"DomainGetInfoResult"`
+	} `xml:"CommandResponse"`
+}
+
+// registrarLockResponse is the response of domains.getRegistrarLock
+type registrarLockResponse struct {
+	XMLName         xml.Name `xml:"ApiResponse"`
+	CommandResponse struct {
+		DomainGetRegistrarLockResult struct {
+			Domain              string `xml:"Domain,attr"`
+			RegistrarLockStatus Bool   `xml:"RegistrarLockStatus,attr"`
+		} `xml:"DomainGetRegistrarLockResult"`
+	} `xml:"CommandResponse"`
+}
+
+// GetDomainInfo returns the registration, WhoisGuard and DNS details of a domain
+func (c *Client) GetDomainInfo(ctx context.Context, domainName string) (*DomainInfo, error) {
+	response := &domainInfoResponse{}
+	err := c.call(ctx, "domains.getInfo", url.Values{"DomainName": {domainName}}, nil, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.CommandResponse.DomainGetInfoResult, nil
+}
+
+// GetRegistrarLock reports whether the domain is locked against transfers
+func (c *Client) GetRegistrarLock(ctx context.Context, domainName string) (bool, error) {
+	response := &registrarLockResponse{}
+	err := c.call(ctx, "domains.getRegistrarLock", url.Values{"DomainName": {domainName}}, nil, response)
+	if err != nil {
+		return false, err
+	}
+	return response.CommandResponse.DomainGetRegistrarLockResult.RegistrarLockStatus.Value, nil
+}
+
+// containsFold reports whether values holds s, in any case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
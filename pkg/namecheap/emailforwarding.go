@@ -0,0 +1,91 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// EmailForward forwards the mail of a mailbox of the domain to another address
+type EmailForward struct {
+	Mailbox   string `xml:"mailbox,attr"`
+	ForwardTo string `xml:",chardata"`
+}
+
+// EmailForwardingResponse is the response of domains.dns.getEmailForwarding. It is also the file format of 'email-forwarding'
+type EmailForwardingResponse struct {
+	XMLName         xml.Name `xml:"ApiResponse"`
+	Status          string   `xml:"Status,attr"`
+	CommandResponse struct {
+		Type                              string `xml:"Type,attr"`
+		DomainDNSGetEmailForwardingResult struct {
+			Domain  string         `xml:"Domain,attr"`
+			Forward []EmailForward `xml:"Forward"`
+		} `xml:"DomainDNSGetEmailForwardingResult"`
+	} `xml:"CommandResponse"`
+	ExecutionTime string `xml:"ExecutionTime"`
+}
+
+// Validate checks the mailbox and the destination address
+func (f EmailForward) Validate() error {
+	if len(f.Mailbox) == 0 || strings.ContainsAny(f.Mailbox, "@ \t") {
+		return fmt.Errorf("mailbox '%s' must be the part before '@', e.g.: 'info'", f.Mailbox)
+	}
+	if _, err := mail.ParseAddress(f.ForwardTo); err != nil {
+		return fmt.Errorf("mailbox '%s' forwards to '%s', which is not an email address", f.Mailbox, f.ForwardTo)
+	}
+	return nil
+}
+
+// Key identifies the forward, in any case
+func (f EmailForward) Key() string {
+	return strings.ToLower(f.Mailbox) + "\x00" + strings.ToLower(f.ForwardTo)
+}
+
+// DiffEmailForwarding returns the forwards of desired missing from current, and the ones of current missing from desired
+func DiffEmailForwarding(current, desired []EmailForward) ([]EmailForward, []EmailForward) {
+	return missingForwards(desired, current), missingForwards(current, desired)
+}
+
+// missingForwards returns the forwards of a that b does not have
+func missingForwards(a, b []EmailForward) []EmailForward {
+	keys := map[string]bool{}
+	for _, f := range b {
+		keys[f.Key()] = true
+	}
+	var missing []EmailForward
+	for _, f := range a {
+		if !keys[f.Key()] {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// GetEmailForwarding downloads the email forwarding of domainName, e.g.: 'example.com'
+func (c *Client) GetEmailForwarding(ctx context.Context, domainName string) (*EmailForwardingResponse, error) {
+	response := &EmailForwardingResponse{}
+	err := c.call(ctx, "domains.dns.getEmailForwarding", url.Values{"DomainName": {domainName}}, nil, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SetEmailForwarding replaces all email forwarding of domainName with forwards
+func (c *Client) SetEmailForwarding(ctx context.Context, domainName string, forwards []EmailForward) error {
+	body := url.Values{}
+	for i, f := range forwards {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		n := strconv.Itoa(i + 1)
+		body.Set("MailBox"+n, f.Mailbox)
+		body.Set("ForwardTo"+n, f.ForwardTo)
+	}
+	return c.call(ctx, "domains.dns.setEmailForwarding", url.Values{"DomainName": {domainName}}, body, &ApiResponse{})
+}
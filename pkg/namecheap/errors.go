@@ -0,0 +1,66 @@
+package namecheap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Error numbers returned by the API
+const (
+	ErrNumberApiUserMissing      = "1010101"
+	ErrNumberApiKeyMissing       = "1010102"
+	ErrNumberInvalidApiKey       = "1011102"
+	ErrNumberParameterMissing    = "2010324"
+	ErrNumberDomainNotFound      = "2019166"
+	ErrNumberDomainNotAssociated = "2016166"
+	ErrNumberUnknownCommand      = "2010000"
+	ErrNumberInvalidHostRecord   = "2050900"
+	ErrNumberUnableToProcess     = "4023330"
+	ErrNumberTooManyRequests     = "500000"
+)
+
+// ErrMissingCredentials is returned by NewClient when the API user or key is empty
+var ErrMissingCredentials = errors.New("api user and api key are required")
+
+// APIError is returned when the API responds with a status other than OK
+type APIError struct {
+	Command string
+	Errors  []Message
+}
+
+func (e *APIError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, m := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", m.Number, m.Text))
+	}
+	return fmt.Sprintf("received errors from the api server for %s: %s", e.Command, strings.Join(messages, "; "))
+}
+
+// HasNumber reports whether any of the returned errors has the given number
+func (e *APIError) HasNumber(number string) bool {
+	for _, m := range e.Errors {
+		if m.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRateLimited reports whether the request was refused for exceeding the API rate limits
+func (e *APIError) IsRateLimited() bool {
+	return e.HasNumber(ErrNumberTooManyRequests)
+}
+
+// HTTPError is returned when the API responds with an unexpected HTTP status code
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter is the delay asked for by a Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected http status: %s", e.Status)
+}
@@ -1,6 +1,10 @@
 package namecheap
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
 
 // ApiResponse was generated 2023-02-16 09:03:21 by https://xml-to-go.github.io/ in Ukraine.
 type ApiResponse struct {
@@ -10,17 +14,11 @@ type ApiResponse struct {
 	Xmlns  string `xml:"xmlns,attr"`
 	Errors struct {
 		// Text  string `xml:",chardata"`
-		Error []struct {
-			Text   string `xml:",chardata"`
-			Number string `xml:"Number,attr"`
-		} `xml:"Error"`
+		Error []Message `xml:"Error"`
 	} `xml:"Errors"`
 	Warnings struct {
 		// Text  string `xml:",chardata"`
-		Warning []struct {
-			Text   string `xml:",chardata"`
-			Number string `xml:"Number,attr"`
-		} `xml:"Warning"`
+		Warning []Message `xml:"Warning"`
 	} `xml:"Warnings"`
 	RequestedCommand string `xml:"RequestedCommand"`
 	CommandResponse  struct {
@@ -39,16 +37,45 @@ type ApiResponse struct {
 	ExecutionTime     string `xml:"ExecutionTime"`
 }
 
+// Message is an error or warning entry returned by the API
+type Message struct {
+	Text   string `xml:",chardata"`
+	Number string `xml:"Number,attr"`
+}
+
+// Host is a DNS record. Numbers and booleans left empty in a file stay unset, see Int and Bool
 type Host struct {
 	// Text               string `xml:",chardata"`
-	HostId             string `xml:"HostId,attr"`
-	Name               string `xml:"Name,attr"`
-	Type               string `xml:"Type,attr"`
-	Address            string `xml:"Address,attr"`
-	MXPref             string `xml:"MXPref,attr"`
-	TTL                string `xml:"TTL,attr"`
-	AssociatedAppTitle string `xml:"AssociatedAppTitle,attr"`
-	FriendlyName       string `xml:"FriendlyName,attr"`
-	IsActive           string `xml:"IsActive,attr"`
-	IsDDNSEnabled      string `xml:"IsDDNSEnabled,attr"`
+	HostId             string     `xml:"HostId,attr"`
+	Name               string     `xml:"Name,attr"`
+	Type               RecordType `xml:"Type,attr"`
+	Address            string     `xml:"Address,attr"`
+	MXPref             Int        `xml:"MXPref,attr" yaml:",omitempty"`
+	TTL                Int        `xml:"TTL,attr" yaml:",omitempty"`
+	AssociatedAppTitle string     `xml:"AssociatedAppTitle,attr"`
+	FriendlyName       string     `xml:"FriendlyName,attr"`
+	IsActive           Bool       `xml:"IsActive,attr" yaml:",omitempty"`
+	IsDDNSEnabled      Bool       `xml:"IsDDNSEnabled,attr" yaml:",omitempty"`
+	// Flag and Tag are only used by CAA records. When Tag is empty, they are read from Address, e.g.: '0 issue letsencrypt.org'
+	Flag string `xml:"Flag,attr,omitempty" yaml:",omitempty" json:",omitempty"`
+	Tag  string `xml:"Tag,attr,omitempty" yaml:",omitempty" json:",omitempty"`
+}
+
+// EmailTypes are the mail settings accepted by setHosts
+var EmailTypes = []string{"MX", "MXE", "FWD", "OX", "GMAIL"}
+
+// CAA splits a CAA record into flag, tag and value
+func (h Host) CAA() (string, string, string, error) {
+	if len(h.Tag) > 0 {
+		flag := h.Flag
+		if len(flag) == 0 {
+			flag = "0"
+		}
+		return flag, h.Tag, strings.Trim(h.Address, `"`), nil
+	}
+	fields := strings.Fields(h.Address)
+	if len(fields) < 3 {
+		return "", "", "", fmt.Errorf("CAA record '%s' needs a flag, a tag and a value, got '%s'", h.Name, h.Address)
+	}
+	return fields[0], fields[1], strings.Trim(strings.Join(fields[2:], " "), `"`), nil
 }
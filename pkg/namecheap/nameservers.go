@@ -0,0 +1,61 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Nameservers is the result of domains.dns.getList
+type Nameservers struct {
+	Domain        string   `xml:"Domain,attr" json:"domain" yaml:"domain"`
+	IsUsingOurDNS Bool     `xml:"IsUsingOurDNS,attr" json:"isUsingOurDNS" yaml:"isUsingOurDNS"`
+	Nameserver    []string `xml:"Nameserver" json:"nameservers" yaml:"nameservers"`
+}
+
+// nameserversResponse is the response of domains.dns.getList
+type nameserversResponse struct {
+	XMLName         xml.Name `xml:"ApiResponse"`
+	CommandResponse struct {
+		DomainDNSGetListResult Nameservers `xml:"DomainDNSGetListResult"`
+	} `xml:"CommandResponse"`
+}
+
+// UsesOurDNS reports whether the host records apply, i.e. the domain uses Namecheap nameservers.
+// An unknown value, e.g. from a file that does not set it, counts as true
+func (r *ApiResponse) UsesOurDNS() bool {
+	b, err := ParseBool(r.CommandResponse.DomainDNSGetHostsResult.IsUsingOurDNS)
+	return err != nil || !b.IsFalse()
+}
+
+// GetNameservers returns the nameservers of sld.tld and whether they are Namecheap's
+func (c *Client) GetNameservers(ctx context.Context, sld, tld string) (*Nameservers, error) {
+	response := &nameserversResponse{}
+	if err := c.call(ctx, "domains.dns.getList", domainParams(sld, tld), nil, response); err != nil {
+		return nil, err
+	}
+	return &response.CommandResponse.DomainDNSGetListResult, nil
+}
+
+// SetDefaultNameservers switches sld.tld to Namecheap BasicDNS, so its host records apply again
+func (c *Client) SetDefaultNameservers(ctx context.Context, sld, tld string) error {
+	return c.call(ctx, "domains.dns.setDefault", domainParams(sld, tld), nil, &ApiResponse{})
+}
+
+// SetCustomNameservers delegates sld.tld to external nameservers. Host records kept at Namecheap stop having any effect
+func (c *Client) SetCustomNameservers(ctx context.Context, sld, tld string, nameservers []string) error {
+	if len(nameservers) == 0 {
+		return fmt.Errorf("at least one nameserver is required")
+	}
+	names := make([]string, 0, len(nameservers))
+	for _, ns := range nameservers {
+		if err := validateTarget(ns); err != nil {
+			return fmt.Errorf("nameserver '%s': %w", ns, err)
+		}
+		names = append(names, strings.ToLower(strings.TrimSuffix(ns, ".")))
+	}
+	params := domainParams(sld, tld)
+	params.Set("Nameservers", strings.Join(names, ","))
+	return c.call(ctx, "domains.dns.setCustom", params, nil, &ApiResponse{})
+}
@@ -0,0 +1,128 @@
+package namecheap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests per Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// DefaultLimits are the documented API limits: 20 requests per minute, 700 per hour and 8000 per day
+var DefaultLimits = []Limit{
+	{Requests: 20, Period: time.Minute},
+	{Requests: 700, Period: time.Hour},
+	{Requests: 8000, Period: 24 * time.Hour},
+}
+
+// ParseLimits parses a comma separated list of limits, e.g.: '20/m,700/h,8000/d'
+func ParseLimits(s string) ([]Limit, error) {
+	periods := map[string]time.Duration{
+		"s": time.Second,
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+	}
+	var limits []Limit
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		requests, unit, found := strings.Cut(item, "/")
+		n, err := strconv.Atoi(requests)
+		period, ok := periods[strings.ToLower(unit)]
+		if !found || err != nil || n <= 0 || !ok {
+			return nil, fmt.Errorf("invalid limit '%s', expected <requests>/<s|m|h|d>", item)
+		}
+		limits = append(limits, Limit{Requests: n, Period: period})
+	}
+	return limits, nil
+}
+
+// RateLimiter is a set of token buckets, one per Limit. A request needs a token from every bucket.
+// It is safe for concurrent use and can be shared by several clients using the same account
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets []*bucket
+	now     func() time.Time
+}
+
+// bucket holds up to capacity tokens, refilled continuously over period
+type bucket struct {
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with full buckets
+func NewRateLimiter(limits ...Limit) *RateLimiter {
+	l := &RateLimiter{now: time.Now}
+	now := l.now()
+	for _, limit := range limits {
+		l.buckets = append(l.buckets, &bucket{
+			capacity: float64(limit.Requests),
+			rate:     float64(limit.Requests) / limit.Period.Seconds(),
+			tokens:   float64(limit.Requests),
+			last:     now,
+		})
+	}
+	return l
+}
+
+// Wait blocks until a request is allowed by all limits or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token from every bucket when all have one and returns 0, otherwise how long to wait for one
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, b := range l.buckets {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+		if b.tokens < 1 {
+			w := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+			if w <= 0 {
+				w = time.Millisecond
+			}
+			if w > wait {
+				wait = w
+			}
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range l.buckets {
+		b.tokens--
+	}
+	return 0
+}
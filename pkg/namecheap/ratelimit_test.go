@@ -0,0 +1,75 @@
+package namecheap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []Limit
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "20/m", want: []Limit{{20, time.Minute}}},
+		{in: "20/m, 700/H,8000/d,", want: []Limit{{20, time.Minute}, {700, time.Hour}, {8000, 24 * time.Hour}}},
+		{in: "5/s", want: []Limit{{5, time.Second}}},
+		{in: "20", wantErr: true},
+		{in: "20/w", wantErr: true},
+		{in: "0/m", wantErr: true},
+		{in: "x/m", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimits(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimits(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLimits(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := NewRateLimiter(Limit{Requests: 2, Period: time.Second}, Limit{Requests: 3, Period: time.Minute})
+	// the buckets were filled at the real time, the fake clock starts there
+	now := l.buckets[0].last
+	l.now = func() time.Time { return now }
+
+	steps := []struct {
+		advance time.Duration
+		want    time.Duration
+	}{
+		// both buckets start full
+		{0, 0},
+		{0, 0},
+		// the per-second bucket is empty and refills one token every 500ms
+		{0, 500 * time.Millisecond},
+		{500 * time.Millisecond, 0},
+		// the per-minute bucket is now empty, refilled 1.5s worth and needs one token every 20s
+		{time.Second, 18500 * time.Millisecond},
+		{18500 * time.Millisecond, 0},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		if got := l.reserve(); got < step.want-time.Millisecond || got > step.want+time.Millisecond {
+			t.Fatalf("step %d: reserve() = %s, want %s", i, got, step.want)
+		}
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	l := NewRateLimiter(Limit{Requests: 1, Period: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("first Wait() = %v", err)
+	}
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Fatalf("Wait() on an empty bucket with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
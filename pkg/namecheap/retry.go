@@ -0,0 +1,92 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy retries requests failing with transient errors: network errors, HTTP 5xx and 429 statuses
+// and the API's 'too many requests' error. Delays double from MinDelay up to MaxDelay, with random jitter
+type RetryPolicy struct {
+	// Retries is how many times a request is sent again. Zero disables retries
+	Retries  int
+	MinDelay time.Duration
+	MaxDelay time.Duration
+	// RateLimitDelay is the least delay after the API refused a request for exceeding its rate limits
+	RateLimitDelay time.Duration
+}
+
+// DefaultRetryPolicy retries 3 times, waiting about 1s, 2s and 4s, or a minute after rate limit errors
+var DefaultRetryPolicy = RetryPolicy{
+	Retries:        3,
+	MinDelay:       time.Second,
+	MaxDelay:       30 * time.Second,
+	RateLimitDelay: time.Minute,
+}
+
+// WithRetryPolicy sets how failed requests are retried. The default is DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// IsRetryable reports whether err is transient, so the same request may succeed later
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRateLimited()
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// delay returns how long to wait before retry attempt, counted from 0, after err
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	d := p.MinDelay << attempt
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	// equal jitter: half fixed, half random, so concurrent clients do not retry in lockstep
+	if d > 1 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)))
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.IsRateLimited() && d < p.RateLimitDelay {
+		d = p.RateLimitDelay
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && d < httpErr.RetryAfter {
+		d = httpErr.RetryAfter
+	}
+	return d
+}
+
+// callWithRetry performs the request with call, retrying transient errors as long as ctx is not done
+func (c *Client) callWithRetry(ctx context.Context, command string, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= c.retry.Retries || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		wait := c.retry.delay(attempt, err)
+		c.debugf("Retrying %s in %s, attempt %d of %d: %v", commandPrefix+command, wait, attempt+1, c.retry.Retries, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package namecheap
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RecordType is the type of a host record. It is upper cased when read
+type RecordType string
+
+// Record types accepted by setHosts
+const (
+	RecordTypeA      RecordType = "A"
+	RecordTypeAAAA   RecordType = "AAAA"
+	RecordTypeALIAS  RecordType = "ALIAS"
+	RecordTypeCAA    RecordType = "CAA"
+	RecordTypeCNAME  RecordType = "CNAME"
+	RecordTypeFRAME  RecordType = "FRAME"
+	RecordTypeMX     RecordType = "MX"
+	RecordTypeMXE    RecordType = "MXE"
+	RecordTypeNS     RecordType = "NS"
+	RecordTypeSRV    RecordType = "SRV"
+	RecordTypeTXT    RecordType = "TXT"
+	RecordTypeURL    RecordType = "URL"
+	RecordTypeURL301 RecordType = "URL301"
+)
+
+// RecordTypes lists all known record types
+var RecordTypes = []RecordType{
+	RecordTypeA, RecordTypeAAAA, RecordTypeALIAS, RecordTypeCAA, RecordTypeCNAME, RecordTypeFRAME, RecordTypeMX,
+	RecordTypeMXE, RecordTypeNS, RecordTypeSRV, RecordTypeTXT, RecordTypeURL, RecordTypeURL301,
+}
+
+// IsKnown reports whether t is one of RecordTypes
+func (t RecordType) IsKnown() bool {
+	for _, known := range RecordTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalText upper cases the type, so 'cname' and 'CNAME' are the same
+func (t *RecordType) UnmarshalText(text []byte) error {
+	*t = RecordType(strings.ToUpper(strings.TrimSpace(string(text))))
+	return nil
+}
+
+// Int is a number that can be left unset. Existing files hold numbers as strings, e.g.: TTL="1799", which are still accepted
+type Int struct {
+	Value int
+	Valid bool
+}
+
+// NewInt returns a set Int
+func NewInt(v int) Int {
+	return Int{Value: v, Valid: true}
+}
+
+// ParseInt parses a decimal number. An empty string is an unset Int
+func ParseInt(s string) (Int, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return Int{}, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return Int{}, fmt.Errorf("'%s' is not a number", s)
+	}
+	return NewInt(v), nil
+}
+
+// String returns the number, or an empty string when unset
+func (i Int) String() string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.Itoa(i.Value)
+}
+
+// IsZero reports whether i is unset, so YAML omitempty leaves it out
+func (i Int) IsZero() bool {
+	return !i.Valid
+}
+
+// UnmarshalText is used by YAML and for XML attributes
+func (i *Int) UnmarshalText(text []byte) error {
+	v, err := ParseInt(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+
+// MarshalXMLAttr leaves out unset numbers
+func (i Int) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if !i.Valid {
+		return xml.Attr{}, nil
+	}
+	return xml.Attr{Name: name, Value: i.String()}, nil
+}
+
+// UnmarshalXMLAttr parses the attribute value
+func (i *Int) UnmarshalXMLAttr(attr xml.Attr) error {
+	return i.UnmarshalText([]byte(attr.Value))
+}
+
+// MarshalJSON writes a number, or null when unset
+func (i Int) MarshalJSON() ([]byte, error) {
+	if !i.Valid {
+		return []byte("null"), nil
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalJSON accepts a number, a string holding one, or null
+func (i *Int) UnmarshalJSON(data []byte) error {
+	text, err := jsonScalar(data)
+	if err != nil {
+		return err
+	}
+	return i.UnmarshalText([]byte(text))
+}
+
+// MarshalYAML writes a number, or null when unset
+func (i Int) MarshalYAML() (interface{}, error) {
+	if !i.Valid {
+		return nil, nil
+	}
+	return i.Value, nil
+}
+
+// Bool is a boolean that can be left unset. Existing files hold booleans as strings, e.g.: IsActive="true", which are still accepted
+type Bool struct {
+	Value bool
+	Valid bool
+}
+
+// NewBool returns a set Bool
+func NewBool(v bool) Bool {
+	return Bool{Value: v, Valid: true}
+}
+
+// ParseBool parses 'true', 'false' and the other values accepted by strconv.ParseBool. An empty string is an unset Bool
+func ParseBool(s string) (Bool, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return Bool{}, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return Bool{}, fmt.Errorf("'%s' is not a boolean", s)
+	}
+	return NewBool(v), nil
+}
+
+// String returns 'true' or 'false', or an empty string when unset
+func (b Bool) String() string {
+	if !b.Valid {
+		return ""
+	}
+	return strconv.FormatBool(b.Value)
+}
+
+// IsZero reports whether b is unset, so YAML omitempty leaves it out
+func (b Bool) IsZero() bool {
+	return !b.Valid
+}
+
+// IsFalse reports whether b is set to false
+func (b Bool) IsFalse() bool {
+	return b.Valid && !b.Value
+}
+
+// UnmarshalText is used by YAML and for XML attributes
+func (b *Bool) UnmarshalText(text []byte) error {
+	v, err := ParseBool(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// MarshalXMLAttr leaves out unset booleans
+func (b Bool) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if !b.Valid {
+		return xml.Attr{}, nil
+	}
+	return xml.Attr{Name: name, Value: b.String()}, nil
+}
+
+// UnmarshalXMLAttr parses the attribute value
+func (b *Bool) UnmarshalXMLAttr(attr xml.Attr) error {
+	return b.UnmarshalText([]byte(attr.Value))
+}
+
+// MarshalJSON writes a boolean, or null when unset
+func (b Bool) MarshalJSON() ([]byte, error) {
+	if !b.Valid {
+		return []byte("null"), nil
+	}
+	return []byte(b.String()), nil
+}
+
+// UnmarshalJSON accepts a boolean, a string holding one, or null
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	text, err := jsonScalar(data)
+	if err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(text))
+}
+
+// MarshalYAML writes a boolean, or null when unset
+func (b Bool) MarshalYAML() (interface{}, error) {
+	if !b.Valid {
+		return nil, nil
+	}
+	return b.Value, nil
+}
+
+// jsonScalar returns a JSON string unquoted, null as an empty string and any other scalar as is
+func jsonScalar(data []byte) (string, error) {
+	text := strings.TrimSpace(string(data))
+	switch {
+	case text == "null":
+		return "", nil
+	case strings.HasPrefix(text, `"`):
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	return text, nil
+}
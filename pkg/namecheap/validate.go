@@ -0,0 +1,234 @@
+package namecheap
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinTTL and MaxTTL are the TTL bounds accepted by Namecheap
+	MinTTL = 60
+	MaxTTL = 60000
+)
+
+// ValidationError is a problem found in a host before it is uploaded
+type ValidationError struct {
+	Host    Host
+	Message string
+}
+
+// Error returns the host and the problem on a single line
+func (e *ValidationError) Error() string {
+	name := e.Host.Name
+	if len(name) == 0 {
+		name = "@"
+	}
+	return fmt.Sprintf("%s %s '%s': %s", name, e.Host.Type, e.Host.Address, e.Message)
+}
+
+// ValidationErrors are all problems found in a set of hosts
+type ValidationErrors []*ValidationError
+
+// Error returns one problem per line
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateHosts checks every host on its own, then the hosts together for CNAME conflicts.
+// Empty hosts, the ones cleared for deletion, are ignored
+func ValidateHosts(hosts []Host) ValidationErrors {
+	var errs ValidationErrors
+	byName := map[string][]Host{}
+	for _, h := range hosts {
+		if h.IsEmpty() {
+			continue
+		}
+		for _, message := range validateHost(h) {
+			errs = append(errs, &ValidationError{Host: h, Message: message})
+		}
+		name := strings.ToLower(h.Name)
+		if name == "" {
+			name = "@"
+		}
+		byName[name] = append(byName[name], h)
+	}
+
+	for _, h := range hosts {
+		if !h.isType(RecordTypeCNAME) {
+			continue
+		}
+		name := strings.ToLower(h.Name)
+		if name == "" {
+			name = "@"
+		}
+		if name == "@" {
+			errs = append(errs, &ValidationError{Host: h, Message: "CNAME is not allowed at the apex, use ALIAS instead"})
+		}
+		for _, other := range byName[name] {
+			if other != h {
+				errs = append(errs, &ValidationError{Host: h, Message: fmt.Sprintf("CNAME cannot coexist with other records of the same name, found %s '%s'", other.Type, other.Address)})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// validateHost returns the problems of a single host
+func validateHost(h Host) []string {
+	var problems []string
+	if !RecordType(strings.ToUpper(string(h.Type))).IsKnown() {
+		return []string{fmt.Sprintf("unknown type, expected one of %v", RecordTypes)}
+	}
+	if err := validateName(h.Name); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if h.TTL.Valid && (h.TTL.Value < MinTTL || h.TTL.Value > MaxTTL) {
+		problems = append(problems, fmt.Sprintf("TTL %d is not between %d and %d", h.TTL.Value, MinTTL, MaxTTL))
+	}
+	if len(h.Address) == 0 {
+		return append(problems, "the value is empty")
+	}
+
+	switch RecordType(strings.ToUpper(string(h.Type))) {
+	case RecordTypeA, RecordTypeMXE:
+		if ip := net.ParseIP(h.Address); ip == nil || ip.To4() == nil {
+			problems = append(problems, "not an IPv4 address")
+		}
+	case RecordTypeAAAA:
+		if ip := net.ParseIP(h.Address); ip == nil || ip.To4() != nil {
+			problems = append(problems, "not an IPv6 address")
+		}
+	case RecordTypeCNAME, RecordTypeNS, RecordTypeALIAS:
+		if err := validateTarget(h.Address); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if h.isType(RecordTypeNS) && (h.Name == "" || h.Name == "@") {
+			problems = append(problems, "NS records at the apex are set with the nameservers, not as host records")
+		}
+	case RecordTypeMX:
+		if !h.MXPref.Valid {
+			problems = append(problems, "MX record needs a preference (MXPref)")
+		} else if h.MXPref.Value < 0 || h.MXPref.Value > 65535 {
+			problems = append(problems, fmt.Sprintf("MX preference %d is not between 0 and 65535", h.MXPref.Value))
+		}
+		if err := validateTarget(h.Address); err != nil {
+			problems = append(problems, err.Error())
+		}
+	case RecordTypeSRV:
+		problems = append(problems, validateSRV(h)...)
+	case RecordTypeCAA:
+		problems = append(problems, validateCAA(h)...)
+	case RecordTypeURL, RecordTypeURL301, RecordTypeFRAME:
+		address := h.Address
+		if !strings.Contains(address, "://") {
+			address = "http://" + address
+		}
+		if u, err := url.Parse(address); err != nil || len(u.Host) == 0 {
+			problems = append(problems, "not a URL, e.g.: 'http://example.com'")
+		}
+	}
+	return problems
+}
+
+// validateName checks a record name: '@', or labels of letters, digits, hyphens and underscores, optionally starting with '*'
+func validateName(name string) error {
+	if name == "" || name == "@" {
+		return nil
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("name is longer than 253 characters")
+	}
+	for i, label := range strings.Split(name, ".") {
+		if label == "*" && i == 0 {
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return fmt.Errorf("invalid name: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateTarget checks a host name pointed to by CNAME, NS, ALIAS and MX records. A trailing dot is allowed
+func validateTarget(target string) error {
+	target = strings.TrimSuffix(target, ".")
+	if len(target) == 0 || len(target) > 253 {
+		return fmt.Errorf("invalid target host name '%s'", target)
+	}
+	for _, label := range strings.Split(target, ".") {
+		if err := validateLabel(label); err != nil {
+			return fmt.Errorf("invalid target host name: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateLabel checks a single DNS label
+func validateLabel(label string) error {
+	if len(label) == 0 || len(label) > 63 {
+		return fmt.Errorf("label '%s' must have 1 to 63 characters", label)
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return fmt.Errorf("label '%s' cannot start or end with a hyphen", label)
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("label '%s' contains '%c'", label, r)
+		}
+	}
+	return nil
+}
+
+// validateSRV checks a '_service._proto' name and a '<priority> <weight> <port> <target>' value
+func validateSRV(h Host) []string {
+	var problems []string
+	labels := strings.Split(h.Name, ".")
+	if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+		problems = append(problems, "SRV name must start with '_service._proto', e.g.: '_sip._tcp'")
+	}
+	fields := strings.Fields(h.Address)
+	if len(fields) != 4 {
+		return append(problems, "SRV value must be '<priority> <weight> <port> <target>'")
+	}
+	for i, field := range []string{"priority", "weight", "port"} {
+		if n, err := strconv.Atoi(fields[i]); err != nil || n < 0 || n > 65535 {
+			problems = append(problems, fmt.Sprintf("SRV %s '%s' is not between 0 and 65535", field, fields[i]))
+		}
+	}
+	if fields[3] != "." {
+		if err := validateTarget(fields[3]); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// validateCAA checks the flag, tag and value of a CAA record
+func validateCAA(h Host) []string {
+	flag, tag, value, err := h.CAA()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	if n, err := strconv.Atoi(flag); err != nil || n < 0 || n > 255 {
+		problems = append(problems, fmt.Sprintf("CAA flag '%s' is not between 0 and 255", flag))
+	}
+	switch strings.ToLower(tag) {
+	case "issue", "issuewild":
+	case "iodef":
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https") {
+			problems = append(problems, fmt.Sprintf("CAA iodef value '%s' must be a mailto:, http: or https: URL", value))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("CAA tag '%s' is not one of issue, issuewild, iodef", tag))
+	}
+	return problems
+}
@@ -0,0 +1,52 @@
+package namecheap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateHosts(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []Host
+		want  []string
+	}{
+		{
+			name: "valid",
+			hosts: []Host{
+				{Name: "@", Type: RecordTypeA, Address: "192.0.2.1", TTL: NewInt(1799)},
+				{Name: "@", Type: RecordTypeMX, Address: "mail.example.com.", MXPref: NewInt(10)},
+				{Name: "www", Type: RecordTypeCNAME, Address: "example.com."},
+				{Name: "_sip._tcp", Type: RecordTypeSRV, Address: "10 60 5060 sip.example.com."},
+				{Name: "@", Type: RecordTypeCAA, Address: `0 issue "letsencrypt.org"`},
+			},
+		},
+		{name: "empty hosts are ignored", hosts: []Host{{}, {Name: "@", Type: RecordTypeA, Address: "192.0.2.1"}}},
+		{name: "bad IPv4", hosts: []Host{{Name: "@", Type: RecordTypeA, Address: "2001:db8::1"}}, want: []string{"not an IPv4 address"}},
+		{name: "TTL out of bounds", hosts: []Host{{Name: "@", Type: RecordTypeA, Address: "192.0.2.1", TTL: NewInt(30)}}, want: []string{"TTL 30"}},
+		{name: "MX without preference", hosts: []Host{{Name: "@", Type: RecordTypeMX, Address: "mail.example.com."}}, want: []string{"needs a preference"}},
+		{name: "SRV without port", hosts: []Host{{Name: "_sip._tcp", Type: RecordTypeSRV, Address: "10 60 sip.example.com."}}, want: []string{"SRV value"}},
+		{name: "CNAME at the apex", hosts: []Host{{Name: "@", Type: RecordTypeCNAME, Address: "example.net."}}, want: []string{"not allowed at the apex"}},
+		{
+			name: "CNAME next to other records",
+			hosts: []Host{
+				{Name: "www", Type: RecordTypeCNAME, Address: "example.com."},
+				{Name: "WWW", Type: RecordTypeA, Address: "192.0.2.1"},
+			},
+			want: []string{"cannot coexist"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateHosts(tt.hosts)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d problems, want %d:\n%v", len(errs), len(tt.want), errs)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package zonefile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// token is a field of a record. Quoted tokens have their quotes and escapes removed
+type token struct {
+	value  string
+	quoted bool
+}
+
+// record is a logical line of a zone file, with parentheses already joined
+type record struct {
+	line       int
+	blankOwner bool
+	tokens     []token
+}
+
+// lex splits a zone file into logical records, handling comments, quotes, escapes and parentheses
+func lex(data []byte) ([]record, error) {
+	var (
+		records    []record
+		current    record
+		field      strings.Builder
+		inField    bool
+		inQuote    bool
+		parens     int
+		line       = 1
+		lineStart  = true
+		inComment  bool
+		fieldQuote bool
+	)
+
+	flushField := func() {
+		if inField {
+			current.tokens = append(current.tokens, token{value: field.String(), quoted: fieldQuote})
+			field.Reset()
+			inField = false
+			fieldQuote = false
+		}
+	}
+	flushRecord := func() {
+		flushField()
+		if len(current.tokens) > 0 {
+			records = append(records, current)
+		}
+		current = record{}
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if lineStart && parens == 0 {
+			current.line = line
+			current.blankOwner = c == ' ' || c == '\t'
+			lineStart = false
+		}
+
+		if inComment {
+			if c != '\n' {
+				continue
+			}
+			inComment = false
+		}
+
+		if inQuote {
+			switch c {
+			case '"':
+				inQuote = false
+			case '\\':
+				escaped, n, err := unescape(data[i+1:])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				field.WriteString(escaped)
+				i += n
+			case '\n':
+				return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+			default:
+				field.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case ';':
+			flushField()
+			inComment = true
+		case '"':
+			inQuote = true
+			inField = true
+			fieldQuote = true
+		case '(':
+			flushField()
+			parens++
+		case ')':
+			flushField()
+			if parens == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced ')'", line)
+			}
+			parens--
+		case ' ', '\t', '\r':
+			flushField()
+		case '\n':
+			line++
+			lineStart = parens == 0
+			if parens == 0 {
+				flushRecord()
+			} else {
+				flushField()
+			}
+		case '\\':
+			escaped, n, err := unescape(data[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			field.WriteString(escaped)
+			inField = true
+			i += n
+		default:
+			field.WriteByte(c)
+			inField = true
+		}
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+	}
+	if parens > 0 {
+		return nil, fmt.Errorf("line %d: unbalanced '('", line)
+	}
+	flushRecord()
+
+	return records, nil
+}
+
+// unescape decodes the character following a backslash, either \X or \DDD, returning the bytes consumed
+func unescape(rest []byte) (string, int, error) {
+	if len(rest) == 0 {
+		return "", 0, fmt.Errorf("dangling escape")
+	}
+	if rest[0] >= '0' && rest[0] <= '9' {
+		if len(rest) < 3 {
+			return "", 0, fmt.Errorf("invalid decimal escape")
+		}
+		value := 0
+		for _, d := range rest[:3] {
+			if d < '0' || d > '9' {
+				return "", 0, fmt.Errorf("invalid decimal escape")
+			}
+			value = value*10 + int(d-'0')
+		}
+		if value > 255 {
+			return "", 0, fmt.Errorf("decimal escape out of range")
+		}
+		return string([]byte{byte(value)}), 3, nil
+	}
+	return string(rest[0]), 1, nil
+}
@@ -0,0 +1,101 @@
+package zonefile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []record
+	}{
+		{
+			name: "fields",
+			in:   "www 300 IN A 192.0.2.1\n",
+			want: []record{{line: 1, tokens: tokens("www", "300", "IN", "A", "192.0.2.1")}},
+		},
+		{
+			name: "comments and empty lines",
+			in:   "; header\n\n@ A 192.0.2.1 ; apex\n",
+			want: []record{{line: 3, tokens: tokens("@", "A", "192.0.2.1")}},
+		},
+		{
+			name: "blank owner",
+			in:   "www A 192.0.2.1\n\tAAAA 2001:db8::1\n",
+			want: []record{
+				{line: 1, tokens: tokens("www", "A", "192.0.2.1")},
+				{line: 2, blankOwner: true, tokens: tokens("AAAA", "2001:db8::1")},
+			},
+		},
+		{
+			name: "quoted strings",
+			in:   `@ TXT "v=spf1 ~all" "a;b" ""` + "\n",
+			want: []record{{line: 1, tokens: []token{{"@", false}, {"TXT", false}, {"v=spf1 ~all", true}, {"a;b", true}, {"", true}}}},
+		},
+		{
+			name: "escapes",
+			in:   `@ TXT "say \"hi\" \\ \065" a\ b` + "\n",
+			want: []record{{line: 1, tokens: []token{{"@", false}, {"TXT", false}, {`say "hi" \ A`, true}, {"a b", false}}}},
+		},
+		{
+			name: "parentheses join lines",
+			in:   "@ SOA ns1 admin (\n  1 ; serial\n  3600 )\nwww A 192.0.2.1\n",
+			want: []record{
+				{line: 1, tokens: tokens("@", "SOA", "ns1", "admin", "1", "3600")},
+				{line: 4, tokens: tokens("www", "A", "192.0.2.1")},
+			},
+		},
+		{
+			name: "no trailing newline",
+			in:   "www\tCNAME\t@",
+			want: []record{{line: 1, tokens: tokens("www", "CNAME", "@")}},
+		},
+		{
+			name: "CRLF line endings",
+			in:   "www A 192.0.2.1\r\n",
+			want: []record{{line: 1, tokens: tokens("www", "A", "192.0.2.1")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lex([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lex() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"@ TXT \"open\n", "line 1: unterminated quoted string"},
+		{"@ TXT \"open", "line 1: unterminated quoted string"},
+		{"@ SOA (\n1\n", "line 3: unbalanced '('"},
+		{"\n@ A 192.0.2.1 )\n", "line 2: unbalanced ')'"},
+		{`@ TXT "\256"`, "decimal escape out of range"},
+		{`@ TXT "\1"`, "invalid decimal escape"},
+		{`@ TXT a\`, "dangling escape"},
+	}
+	for _, tt := range tests {
+		if _, err := lex([]byte(tt.in)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("lex(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func tokens(values ...string) []token {
+	t := make([]token, 0, len(values))
+	for _, v := range values {
+		t = append(t, token{value: v})
+	}
+	return t
+}
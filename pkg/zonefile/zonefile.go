@@ -0,0 +1,363 @@
+// Package zonefile converts between RFC 1035 master files (BIND zone files) and Namecheap DNS configuration
+package zonefile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+// DefaultTTL is Namecheap's equivalent to 'Automatic'
+const DefaultTTL = 1799
+
+// maxStringLength is the maximum length of a single character-string in TXT rdata
+const maxStringLength = 255
+
+var (
+	// supportedTypes are the record types that exist both in zone files and in Namecheap
+	supportedTypes = []string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "TXT"}
+	// namecheapOnlyTypes are Namecheap record types that have no zone file representation
+	namecheapOnlyTypes = []string{"ALIAS", "FRAME", "MXE", "URL", "URL301"}
+	// classes are the record classes that may precede the type
+	classes = []string{"IN", "CH", "CS", "HS"}
+)
+
+// Unmarshal parses a zone file into v. Records Namecheap cannot represent are skipped and reported as warnings
+func Unmarshal(data []byte, v *namecheap.ApiResponse) ([]string, error) {
+	records, err := lex(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		warnings   []string
+		origin     string
+		defaultTTL = DefaultTTL
+		lastOwner  string
+		hosts      []namecheap.Host
+	)
+	for _, r := range records {
+		tokens := r.tokens
+		if !r.blankOwner && strings.HasPrefix(tokens[0].value, "$") {
+			switch strings.ToUpper(tokens[0].value) {
+			case "$ORIGIN":
+				if len(tokens) < 2 {
+					return nil, fmt.Errorf("line %d: $ORIGIN without a domain name", r.line)
+				}
+				origin = absolute(tokens[1].value, origin)
+			case "$TTL":
+				if len(tokens) < 2 {
+					return nil, fmt.Errorf("line %d: $TTL without a value", r.line)
+				}
+				ttl, ok := parseTTL(tokens[1].value)
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid $TTL '%s'", r.line, tokens[1].value)
+				}
+				defaultTTL = ttl
+			default:
+				return nil, fmt.Errorf("line %d: directive %s is not supported", r.line, tokens[0].value)
+			}
+			continue
+		}
+
+		owner := lastOwner
+		i := 0
+		if !r.blankOwner {
+			owner = absolute(tokens[0].value, origin)
+			i = 1
+		}
+		if len(owner) == 0 {
+			return nil, fmt.Errorf("line %d: record without owner name", r.line)
+		}
+		lastOwner = owner
+
+		ttl := defaultTTL
+		for n := 0; n < 2 && i < len(tokens); n++ {
+			if t, ok := parseTTL(tokens[i].value); ok {
+				ttl = t
+				i++
+			} else if containsFold(classes, tokens[i].value) {
+				i++
+			}
+		}
+		if i >= len(tokens) {
+			return nil, fmt.Errorf("line %d: record without type", r.line)
+		}
+		recordType := strings.ToUpper(tokens[i].value)
+		rdata := tokens[i+1:]
+
+		if recordType == "SOA" {
+			// the SOA owner is the zone apex
+			if len(origin) == 0 {
+				origin = owner
+			}
+			continue
+		}
+
+		name, ok := relative(owner, origin)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("line %d: skipping %s record of '%s', which is outside of origin '%s'", r.line, recordType, owner, origin))
+			continue
+		}
+		if !containsFold(supportedTypes, recordType) {
+			warnings = append(warnings, fmt.Sprintf("line %d: skipping %s record of '%s', type is not supported by Namecheap", r.line, recordType, name))
+			continue
+		}
+		if recordType == "NS" && name == "@" {
+			warnings = append(warnings, fmt.Sprintf("line %d: skipping apex NS record, nameservers are not managed by host records", r.line))
+			continue
+		}
+
+		host, err := toHost(name, recordType, ttl, rdata, origin)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		host.HostId = strconv.Itoa(len(hosts) + 1)
+		hosts = append(hosts, host)
+	}
+
+	v.Status = "OK"
+	v.CommandResponse.Type = "namecheap.domains.dns.getHosts"
+	v.CommandResponse.DomainDNSGetHostsResult.Domain = strings.TrimSuffix(origin, ".")
+	v.CommandResponse.DomainDNSGetHostsResult.Host = hosts
+
+	return warnings, nil
+}
+
+// Marshal renders the hosts of v as a zone file. Hosts that cannot be represented are written as comments and reported as warnings
+func Marshal(v *namecheap.ApiResponse) ([]byte, []string, error) {
+	var (
+		warnings []string
+		b        bytes.Buffer
+	)
+
+	domain := strings.TrimSuffix(v.CommandResponse.DomainDNSGetHostsResult.Domain, ".")
+	if len(domain) > 0 {
+		fmt.Fprintf(&b, "$ORIGIN %s.\n", domain)
+	}
+	fmt.Fprintf(&b, "$TTL %d\n", DefaultTTL)
+
+	hosts := make([]namecheap.Host, 0, len(v.CommandResponse.DomainDNSGetHostsResult.Host))
+	for _, h := range v.CommandResponse.DomainDNSGetHostsResult.Host {
+		if !h.IsEmpty() {
+			hosts = append(hosts, h)
+		}
+	}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		if hosts[i].Name != hosts[j].Name {
+			return hosts[i].Name < hosts[j].Name
+		}
+		return hosts[i].Type < hosts[j].Type
+	})
+
+	for _, h := range hosts {
+		name := h.Name
+		if len(name) == 0 {
+			name = "@"
+		}
+		ttl := h.TTL
+		if !ttl.Valid {
+			ttl = namecheap.NewInt(DefaultTTL)
+		}
+		recordType := strings.ToUpper(string(h.Type))
+
+		rdata, err := rdataOf(h, recordType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("record '%s' %s: %w", name, recordType, err)
+		}
+		line := fmt.Sprintf("%s\t%s\tIN\t%s\t%s", name, ttl, recordType, rdata)
+
+		switch {
+		case containsFold(namecheapOnlyTypes, recordType):
+			warnings = append(warnings, fmt.Sprintf("%s record of '%s' has no zone file equivalent, writing it as a comment", recordType, name))
+			line = "; " + line
+		case !containsFold(supportedTypes, recordType):
+			warnings = append(warnings, fmt.Sprintf("%s record of '%s' is unknown, writing it as a comment", recordType, name))
+			line = "; " + line
+		case h.IsActive.IsFalse():
+			warnings = append(warnings, fmt.Sprintf("%s record of '%s' is inactive, writing it as a comment", recordType, name))
+			line = "; " + line
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes(), warnings, nil
+}
+
+// toHost maps a parsed record to a Namecheap host
+func toHost(name, recordType string, ttl int, rdata []token, origin string) (namecheap.Host, error) {
+	host := namecheap.Host{
+		Name:     name,
+		Type:     namecheap.RecordType(recordType),
+		TTL:      namecheap.NewInt(ttl),
+		IsActive: namecheap.NewBool(true),
+	}
+	if len(rdata) == 0 {
+		return host, fmt.Errorf("%s record without data", recordType)
+	}
+
+	switch recordType {
+	case "A", "AAAA":
+		host.Address = rdata[0].value
+	case "CNAME", "NS":
+		host.Address = absolute(rdata[0].value, origin)
+	case "MX":
+		if len(rdata) < 2 {
+			return host, fmt.Errorf("MX record needs a preference and an exchange")
+		}
+		pref, err := strconv.Atoi(rdata[0].value)
+		if err != nil {
+			return host, fmt.Errorf("invalid MX preference '%s'", rdata[0].value)
+		}
+		host.MXPref = namecheap.NewInt(pref)
+		host.Address = absolute(rdata[1].value, origin)
+	case "TXT":
+		parts := make([]string, 0, len(rdata))
+		for _, t := range rdata {
+			parts = append(parts, t.value)
+		}
+		host.Address = strings.Join(parts, "")
+	case "CAA":
+		if len(rdata) < 3 {
+			return host, fmt.Errorf("CAA record needs flags, tag and value")
+		}
+		host.Address = fmt.Sprintf("%s %s %s", rdata[0].value, rdata[1].value, quote(rdata[2].value))
+	}
+	return host, nil
+}
+
+// rdataOf renders the data part of a host
+func rdataOf(h namecheap.Host, recordType string) (string, error) {
+	switch recordType {
+	case "CNAME", "NS":
+		return fqdn(h.Address), nil
+	case "MX":
+		pref := h.MXPref
+		if !pref.Valid {
+			pref = namecheap.NewInt(10)
+		}
+		return fmt.Sprintf("%s %s", pref, fqdn(h.Address)), nil
+	case "TXT":
+		return quoteSplit(h.Address), nil
+	case "CAA":
+		fields := strings.Fields(h.Address)
+		if len(fields) < 3 {
+			return "", fmt.Errorf("CAA value '%s' needs flags, tag and value", h.Address)
+		}
+		value := strings.Trim(strings.Join(fields[2:], " "), `"`)
+		return fmt.Sprintf("%s %s %s", fields[0], fields[1], quote(value)), nil
+	}
+	return h.Address, nil
+}
+
+// absolute resolves a possibly relative name against origin
+func absolute(name, origin string) string {
+	if name == "@" {
+		return origin
+	}
+	if strings.HasSuffix(name, ".") || len(origin) == 0 {
+		return name
+	}
+	return name + "." + origin
+}
+
+// relative returns the Namecheap host name of an absolute name
+func relative(name, origin string) (string, bool) {
+	if len(origin) == 0 || !strings.HasSuffix(name, ".") {
+		name = strings.TrimSuffix(name, ".")
+		if len(name) == 0 {
+			return "@", true
+		}
+		return name, true
+	}
+	lowerName := strings.ToLower(name)
+	lowerOrigin := strings.ToLower(origin)
+	if lowerName == lowerOrigin {
+		return "@", true
+	}
+	if strings.HasSuffix(lowerName, "."+lowerOrigin) {
+		return name[:len(name)-len(origin)-1], true
+	}
+	return "", false
+}
+
+// fqdn appends the root dot to a domain name, if missing
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// parseTTL parses a TTL in seconds or with BIND units, e.g.: 1h30m
+func parseTTL(s string) (int, bool) {
+	if len(s) == 0 || !unicode.IsDigit(rune(s[0])) {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+	total, current := 0, 0
+	for _, c := range strings.ToLower(s) {
+		if unicode.IsDigit(c) {
+			current = current*10 + int(c-'0')
+			continue
+		}
+		multiplier := map[rune]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}[c]
+		if multiplier == 0 {
+			return 0, false
+		}
+		total += current * multiplier
+		current = 0
+	}
+	return total + current, true
+}
+
+// quote wraps a character-string in double quotes, escaping as needed
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range []byte(s) {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// quoteSplit quotes s as one or more character-strings of at most 255 bytes
+func quoteSplit(s string) string {
+	if len(s) <= maxStringLength {
+		return quote(s)
+	}
+	var parts []string
+	for len(s) > maxStringLength {
+		parts = append(parts, quote(s[:maxStringLength]))
+		s = s[maxStringLength:]
+	}
+	parts = append(parts, quote(s))
+	return strings.Join(parts, " ")
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
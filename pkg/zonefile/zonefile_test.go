@@ -0,0 +1,165 @@
+package zonefile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thedataflows/namecheap-cli/pkg/namecheap"
+)
+
+const testZone = `$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	ns1.example.com. admin.example.com. ( 1 7200 3600 1209600 3600 )
+@		IN	NS	ns1.example.com.
+@	1799	IN	A	192.0.2.1
+	300	IN	AAAA	2001:db8::1
+www		IN	CNAME	@
+@		IN	MX	10 mail
+@		IN	TXT	"v=spf1 include:_spf.example.net ~all"
+@		IN	CAA	0 issue "letsencrypt.org"
+ftp.example.net.	IN	A	192.0.2.9
+@		IN	DS	12345 13 2 ABCDEF
+`
+
+func TestUnmarshal(t *testing.T) {
+	var v namecheap.ApiResponse
+	warnings, err := Unmarshal([]byte(testZone), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := func(id, name string, recordType namecheap.RecordType, address string, ttl int) namecheap.Host {
+		return namecheap.Host{HostId: id, Name: name, Type: recordType, Address: address, TTL: namecheap.NewInt(ttl), IsActive: namecheap.NewBool(true)}
+	}
+	mx := host("4", "@", namecheap.RecordTypeMX, "mail.example.com.", 3600)
+	mx.MXPref = namecheap.NewInt(10)
+	want := []namecheap.Host{
+		host("1", "@", namecheap.RecordTypeA, "192.0.2.1", 1799),
+		host("2", "@", namecheap.RecordTypeAAAA, "2001:db8::1", 300),
+		host("3", "www", namecheap.RecordTypeCNAME, "example.com.", 3600),
+		mx,
+		host("5", "@", namecheap.RecordTypeTXT, "v=spf1 include:_spf.example.net ~all", 3600),
+		host("6", "@", namecheap.RecordTypeCAA, `0 issue "letsencrypt.org"`, 3600),
+	}
+
+	result := v.CommandResponse.DomainDNSGetHostsResult
+	if result.Domain != "example.com" {
+		t.Errorf("domain = %q, want %q", result.Domain, "example.com")
+	}
+	if !reflect.DeepEqual(result.Host, want) {
+		t.Errorf("hosts =\n%+v\nwant\n%+v", result.Host, want)
+	}
+
+	wantWarnings := []string{"apex NS record", "outside of origin", "DS record"}
+	if len(warnings) != len(wantWarnings) {
+		t.Fatalf("warnings = %q, want %d", warnings, len(wantWarnings))
+	}
+	for i, w := range wantWarnings {
+		if !strings.Contains(warnings[i], w) {
+			t.Errorf("warning %d = %q, want it to contain %q", i, warnings[i], w)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"$INCLUDE other.zone\n", "directive $INCLUDE is not supported"},
+		{"$TTL 1x\n", "invalid $TTL"},
+		{"@ 300 IN\n", "record without type"},
+		{"@ MX mail.example.com.\n", "MX record needs a preference"},
+		{"@ MX ten mail.example.com.\n", "invalid MX preference"},
+		{"@ CAA 0 issue\n", "CAA record needs"},
+		{"@ A\n", "A record without data"},
+	}
+	for _, tt := range tests {
+		var v namecheap.ApiResponse
+		if _, err := Unmarshal([]byte("$ORIGIN example.com.\n"+tt.in), &v); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Unmarshal(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	var v namecheap.ApiResponse
+	v.CommandResponse.DomainDNSGetHostsResult.Domain = "example.com"
+	v.CommandResponse.DomainDNSGetHostsResult.Host = []namecheap.Host{
+		{Name: "www", Type: namecheap.RecordTypeCNAME, Address: "example.com", TTL: namecheap.NewInt(300)},
+		{Name: "@", Type: namecheap.RecordTypeMX, Address: "mail.example.com", MXPref: namecheap.NewInt(20)},
+		{Name: "@", Type: namecheap.RecordTypeCAA, Address: "128 issue letsencrypt.org"},
+		{Name: "@", Type: namecheap.RecordTypeTXT, Address: `say "hi"` + strings.Repeat("x", 250)},
+		{Name: "go", Type: namecheap.RecordTypeURL, Address: "https://example.net"},
+		{Name: "old", Type: namecheap.RecordTypeA, Address: "192.0.2.2", IsActive: namecheap.NewBool(false)},
+		{},
+	}
+
+	data, warnings, err := Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "$ORIGIN example.com.\n" +
+		"$TTL 1799\n" +
+		"@\t1799\tIN\tCAA\t128 issue \"letsencrypt.org\"\n" +
+		"@\t1799\tIN\tMX\t20 mail.example.com.\n" +
+		"@\t1799\tIN\tTXT\t\"say \\\"hi\\\"" + strings.Repeat("x", 247) + "\" \"xxx\"\n" +
+		"; go\t1799\tIN\tURL\thttps://example.net\n" +
+		"; old\t1799\tIN\tA\t192.0.2.2\n" +
+		"www\t300\tIN\tCNAME\texample.com.\n"
+	if string(data) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, want)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "no zone file equivalent") || !strings.Contains(warnings[1], "inactive") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	var v namecheap.ApiResponse
+	v.CommandResponse.DomainDNSGetHostsResult.Host = []namecheap.Host{{Name: "@", Type: namecheap.RecordTypeCAA, Address: "letsencrypt.org"}}
+	if _, _, err := Marshal(&v); err == nil {
+		t.Error("Marshal() of a CAA record without flags and tag succeeded")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var first namecheap.ApiResponse
+	if _, err := Unmarshal([]byte(testZone), &first); err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := Marshal(&first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var second namecheap.ApiResponse
+	if _, err := Unmarshal(data, &second); err != nil {
+		t.Fatalf("%v in\n%s", err, data)
+	}
+	diff := namecheap.DiffHosts(first.CommandResponse.DomainDNSGetHostsResult.Host, second.CommandResponse.DomainDNSGetHostsResult.Host)
+	if !diff.IsEmpty() {
+		t.Errorf("round trip changed the hosts:\n%s", diff)
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"3600", 3600, true},
+		{"1h30m", 5400, true},
+		{"1W", 604800, true},
+		{"2d1", 172801, true},
+		{"IN", 0, false},
+		{"1x", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseTTL(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("parseTTL(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}